@@ -0,0 +1,100 @@
+package pjson5
+
+import "fmt"
+
+// BlockType 解析后的block类型，与内部的数据块类型一一对应
+type BlockType int32
+
+const (
+	BlockComment     = BlockType(dataTypeComment)     // 独占一行的注释
+	BlockCommentLine = BlockType(dataTypeCommentLine) // 跟在其他内容之后的同行注释
+	BlockStart       = BlockType(dataTypeStartFlag)   // 对象/数组的起始符号
+	BlockKey         = BlockType(dataTypeKey)         // 对象的key
+	BlockColon       = BlockType(dataTypeColon)       // key与值之间的冒号
+	BlockValue       = BlockType(dataTypeVal)         // 值
+	BlockComma       = BlockType(dataTypeComma)       // 成员之间的逗号
+	BlockEnd         = BlockType(dataTypeEndFlag)     // 对象/数组的结束符号
+	BlockLineBreak   = BlockType(dataTypeLineBreak)   // 换行
+	BlockShebang     = BlockType(dataTypeShebang)     // 文档首行的shebang行
+)
+
+// String 返回block类型的名称
+func (t BlockType) String() string {
+	switch t {
+	case BlockComment:
+		return "Comment"
+	case BlockCommentLine:
+		return "CommentLine"
+	case BlockStart:
+		return "Start"
+	case BlockKey:
+		return "Key"
+	case BlockColon:
+		return "Colon"
+	case BlockValue:
+		return "Value"
+	case BlockComma:
+		return "Comma"
+	case BlockEnd:
+		return "End"
+	case BlockLineBreak:
+		return "LineBreak"
+	case BlockShebang:
+		return "Shebang"
+	}
+	return fmt.Sprintf("BlockType(%d)", int32(t))
+}
+
+// Block 节点解析后的一个数据块，用于在包外实现自定义的渲染
+type Block struct {
+	Type BlockType
+	// Text 块的原文：注释与Shebang包含分隔符及可能的结尾换行，Key为带引号的原文，Colon为原文中冒号前后的空白（可能为空），
+	// 标量的Value为值的原文，容器成员的Value为空
+	Text string
+	// Key Key块为解码转义后的key；容器成员的Value块为对应的key或数组下标
+	Key string
+	// Node Value块对应的值节点，即节点中的子节点本身而非拷贝，容器成员可继续调用其 Blocks 得到嵌套结构
+	Node *Node
+}
+
+// Blocks 按文档顺序返回节点自身的数据块，子节点的内容不会展开。返回的Block是副本，修改其字段不会影响节点；
+// 通过 Block.Node 修改子节点时会反映到当前节点上，需要独立的子节点时使用 GetCopy。解析失败时返回nil
+func (n *Node) Blocks() []Block {
+	if n.parse().Error() != nil {
+		return nil
+	}
+	blocks := make([]Block, 0, len(n.block))
+	var key string
+	for _, block := range n.block {
+		b := Block{Type: BlockType(block.Typ), Text: block.Val}
+		switch block.Typ {
+		case dataTypeStartFlag, dataTypeEndFlag:
+			pair := objectPair
+			if n.typ == Array {
+				pair = arrayPair
+			}
+			b.Text = string(pair[0])
+			if block.Typ == dataTypeEndFlag {
+				b.Text = string(pair[1])
+			}
+		case dataTypeKey:
+			key = block.KeyUnQuot()
+			b.Key = key
+		case dataTypeComma:
+			b.Text = string(comma)
+		case dataTypeLineBreak:
+			b.Text = lineBreak
+		case dataTypeVal:
+			switch n.typ {
+			case Object:
+				b.Text, b.Key, b.Node = "", key, n.children[key]
+			case Array:
+				b.Text, b.Key, b.Node = "", block.Val, n.children[block.Val]
+			default:
+				b.Text, b.Node = n.val, n
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
@@ -0,0 +1,64 @@
+package pjson5
+
+import (
+	"strings"
+	"testing"
+)
+
+// renderBlocks 仅使用导出的Block实现的简易渲染器
+func renderBlocks(buf *strings.Builder, node *Node) {
+	for _, block := range node.Blocks() {
+		switch {
+		case block.Type == BlockValue && block.Node != node:
+			renderBlocks(buf, block.Node)
+		case block.Type == BlockColon:
+			buf.WriteString(":")
+		default:
+			buf.WriteString(block.Text)
+		}
+	}
+}
+
+func TestNode_Blocks(t *testing.T) {
+	buf := &strings.Builder{}
+	renderBlocks(buf, New(rawJson))
+	got := buf.String()
+	for _, want := range []string{"// 首行注释", `"string_key":/*key中注释*/"www.com"`, "[1,2,3,4]", "// 末尾注释"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("rendered blocks missing %q:\n%s", want, got)
+		}
+	}
+	if a, b := mustMarshal(t, New(got)), mustMarshal(t, New(rawJson)); a != b {
+		t.Fatalf("rendered document = %s, want %s", a, b)
+	}
+
+	node := New(`{a: 1}`)
+	blocks := node.Blocks()
+	types := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		types = append(types, block.Type.String())
+	}
+	if got := strings.Join(types, " "); got != "Start Key Colon Value End" {
+		t.Fatalf("Blocks() types = %s", got)
+	}
+	if blocks[1].Key != "a" || blocks[3].Key != "a" || blocks[3].Node.Value() != "1" {
+		t.Fatalf("Blocks() = %+v", blocks)
+	}
+	// Block.Node 为节点中的子节点本身
+	blocks[3].Node.SetRawValue("2")
+	if v := node.Get("a").Value(); v != "2" {
+		t.Fatalf("expected Block.Node to be the live child, got a=%s", v)
+	}
+	if New(`{a: }`).Blocks() != nil {
+		t.Fatal("expected nil blocks for invalid document")
+	}
+}
+
+func mustMarshal(t *testing.T, node *Node) string {
+	t.Helper()
+	data, err := node.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
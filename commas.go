@@ -0,0 +1,37 @@
+package pjson5
+
+import "sort"
+
+// TrailingCommas 返回尾随逗号（紧接在 } 或 ] 之前的逗号，中间可以有空白与注释）在原始文档中的字节偏移，
+// 按出现顺序排列，用于提示而不拒绝这类写法。偏移基于解析时的输入，修改过的节点中新增的内容不会被检查。
+// 文档解析失败时返回nil
+func (n *Node) TrailingCommas() []int {
+	if !n.parseTree() {
+		return nil
+	}
+	offsets := make([]int, 0)
+	n.walk(nil, func(_ []string, node *Node) bool {
+		pending := -1 // 最近一个逗号在block中的位置，之后出现注释与换行以外的block时清除
+		for i, block := range node.block {
+			switch {
+			case block.Typ == dataTypeComma:
+				pending = i
+			case block.Typ == dataTypeEndFlag && pending >= 0:
+				if pos := node.block[pending].Pos; pos > 0 {
+					offsets = append(offsets, pos-1)
+				}
+				pending = -1
+			case !block.Is(dataTypeComment | dataTypeCommentLine | dataTypeLineBreak):
+				pending = -1
+			}
+		}
+		return true
+	})
+	sort.Ints(offsets)
+	return offsets
+}
+
+// commaBlock 返回当前解析位置的逗号block，记录其在原始文档中的位置
+func (n *Node) commaBlock() dataBlock {
+	return dataBlock{Typ: dataTypeComma, Pos: n.offset + n.parseIdx + 1}
+}
@@ -0,0 +1,46 @@
+package pjson5
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNode_TrailingCommas(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string // 尾随逗号之前的内容，用于计算期望的偏移
+	}{
+		{name: "none", data: `{"a": [1, 2], "b": "x,}"}`, want: []string{}},
+		{name: "object", data: `{"a": 1,}`, want: []string{`{"a": 1`}},
+		{name: "array_with_comment", data: "[1, 2, // c\n]", want: []string{"[1, 2"}},
+		{name: "nested", data: "{\"a\": [1,], /* , */ \"b\": {\"c\": 'x',\n},\n}", want: []string{
+			`{"a": [1`, "{\"a\": [1,], /* , */ \"b\": {\"c\": 'x'", "{\"a\": [1,], /* , */ \"b\": {\"c\": 'x',\n}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([]int, 0, len(tt.want))
+			for _, prefix := range tt.want {
+				if !strings.HasPrefix(tt.data, prefix) {
+					t.Fatalf("bad prefix %q", prefix)
+				}
+				want = append(want, len(prefix))
+			}
+			if got := New(tt.data).TrailingCommas(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("TrailingCommas() = %v, want %v", got, want)
+			}
+		})
+	}
+	// 子节点返回的偏移同样基于原始文档
+	if got := New(rawJson).Get("map_key").TrailingCommas(); len(got) != 1 || rawJson[got[0]-len(`[5000]`):got[0]+1] != "[5000]," {
+		t.Fatalf("TrailingCommas() = %v", got)
+	}
+	// 按当前的block结构判断，新增成员之后原来的尾随逗号不再报告
+	if got := New(`{"a": [1,], "b": 2,}`).Set("c", 3).TrailingCommas(); !reflect.DeepEqual(got, []int{8}) {
+		t.Fatalf("TrailingCommas() after Set = %v, want [8]", got)
+	}
+	if New(`{a: [1,}`).TrailingCommas() != nil {
+		t.Fatal("expected nil for invalid document")
+	}
+}
@@ -0,0 +1,305 @@
+package pjson5
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PrependComment 在文档开头（根节点的值之前）插入行注释，多行文本会拆分为多行 // 注释。
+// 文档以shebang行开头时，注释插入在shebang行之后
+func (n *Node) PrependComment(text string) *Node {
+	if n.parse().Error() != nil {
+		return n
+	}
+	lines := strings.Split(text, lineBreak)
+	head := n.headIndex()
+	blocks := make([]dataBlock, 0, len(lines)+len(n.block))
+	blocks = append(blocks, n.block[:head]...)
+	for _, line := range lines {
+		comment := "//"
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			comment += " " + line
+		}
+		blocks = append(blocks, dataBlock{Typ: dataTypeCommentLine, Val: comment + lineBreak})
+	}
+	n.block = append(blocks, n.block[head:]...)
+	return n
+}
+
+// headIndex 返回文档开头的注释在block中的插入位置，shebang行必须保持在首行
+func (n *Node) headIndex() int {
+	if len(n.block) > 0 && n.block[0].Typ == dataTypeShebang {
+		return 1
+	}
+	return 0
+}
+
+// TrailingComments 返回根节点的值之后出现的注释，如文档末尾的签名注释，行注释不包含结尾的换行符
+func (n *Node) TrailingComments() []string {
+	if n.parse().Error() != nil {
+		return nil
+	}
+	endTyp := dataTypeVal
+	if n.typ == Object || n.typ == Array {
+		endTyp = dataTypeEndFlag
+	}
+	var comments []string
+	afterValue := false
+	for _, block := range n.block {
+		if !afterValue {
+			afterValue = block.Typ == endTyp
+			continue
+		}
+		if block.Is(dataTypeComment | dataTypeCommentLine) {
+			comments = append(comments, strings.TrimRight(block.Val, "\r\n"))
+		}
+	}
+	return comments
+}
+
+// ForEachComment 按文档顺序遍历整棵树中的注释，line表示是否为 // 行注释。
+// fn返回替换后的注释文本（返回原文本则不修改），注释后的换行会被保留，替换文本需保持注释语法
+func (n *Node) ForEachComment(fn func(text string, line bool) string) *Node {
+	if n.parse().Error() != nil {
+		return n
+	}
+	var key string
+	for i, block := range n.block {
+		switch {
+		case block.Is(dataTypeComment | dataTypeCommentLine):
+			text := strings.TrimRight(block.Val, " \t\r\n")
+			if newText := fn(text, strings.HasPrefix(text, "//")); newText != text {
+				n.block[i].Val = newText + block.Val[len(text):]
+			}
+		case block.Typ == dataTypeKey:
+			key = block.KeyUnQuot()
+		case block.Typ == dataTypeVal:
+			if n.typ == Array {
+				key = block.Val
+			}
+			child, ok := n.children[key]
+			if !ok {
+				continue
+			}
+			if child.ForEachComment(fn).err != nil {
+				n.err = child.err
+				return n
+			}
+		}
+	}
+	return n
+}
+
+// CommentText 返回注释的正文：去掉 // 与 /* */ 分隔符，以及块注释每行开头的空白和 * 装饰，
+// 并删除首尾的空行。需要保留原始格式时直接使用注释原文
+func CommentText(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "//") {
+		return strings.TrimSpace(strings.TrimPrefix(raw, "//"))
+	}
+	if !strings.HasPrefix(raw, "/*") {
+		return raw
+	}
+	raw = strings.TrimSuffix(strings.TrimPrefix(raw, "/*"), "*/")
+	lines := strings.Split(raw, lineBreak)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i > 0 || strings.HasPrefix(line, "*") { // 首行的 * 来自 /** 写法
+			line = strings.TrimSpace(strings.TrimLeft(line, "*"))
+		}
+		lines[i] = line
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, lineBreak)
+}
+
+// keyComments 返回key关联的注释：key之前独占一行的前置注释、key与值之间的注释以及值之后的同行注释
+func (n *Node) keyComments(key string) (leading, inner, trailing []dataBlock) {
+	start, keyIdx, end := n.keyBlockRange(key)
+	if keyIdx < 0 {
+		return nil, nil, nil
+	}
+	start = n.leadingStart(start)
+	leading = append(leading, n.block[start:keyIdx]...)
+	afterVal := false
+	for _, block := range n.block[keyIdx+1 : end] {
+		switch {
+		case block.Typ == dataTypeVal:
+			afterVal = true
+		case !block.Is(dataTypeComment | dataTypeCommentLine):
+		case afterVal:
+			trailing = append(trailing, block)
+		default:
+			inner = append(inner, block)
+		}
+	}
+	return leading, inner, trailing
+}
+
+// setKeyComments 使用给定的注释替换key原有的关联注释，并补齐注释前后必要的换行
+func (n *Node) setKeyComments(key string, leading, inner, trailing []dataBlock) {
+	start, keyIdx, end := n.keyBlockRange(key)
+	if keyIdx < 0 {
+		return
+	}
+	start = n.leadingStart(start)
+	blocks := make([]dataBlock, 0, len(n.block)+len(leading)+len(inner)+len(trailing)+2)
+	blocks = append(blocks, n.block[:start]...)
+	if len(leading) > 0 && start > 0 && !endsWithLineBreak(n.block[start-1]) {
+		blocks = append(blocks, dataBlock{Typ: dataTypeLineBreak})
+	}
+	blocks = append(blocks, leading...)
+	blocks = append(blocks, n.block[keyIdx])
+	removedLB := false // 删除的同行注释是否包含换行
+	for _, block := range n.block[keyIdx+1 : end] {
+		if block.Is(dataTypeComment | dataTypeCommentLine) {
+			removedLB = removedLB || endsWithLineBreak(block)
+			continue
+		}
+		if block.Typ == dataTypeVal {
+			blocks = append(blocks, inner...)
+		}
+		blocks = append(blocks, block)
+	}
+	blocks = append(blocks, trailing...)
+	rest := n.block[end:]
+	addedLB := len(trailing) > 0 && endsWithLineBreak(trailing[len(trailing)-1])
+	nextIsLB := len(rest) > 0 && rest[0].Typ == dataTypeLineBreak
+	switch {
+	case addedLB && nextIsLB:
+		rest = rest[1:]
+	case removedLB && !addedLB && !nextIsLB && (len(rest) == 0 || rest[0].Typ != dataTypeKey):
+		blocks = append(blocks, dataBlock{Typ: dataTypeLineBreak})
+	}
+	n.block = append(blocks, rest...)
+}
+
+// endsWithLineBreak 判断输出该block后是否已经换行
+func endsWithLineBreak(block dataBlock) bool {
+	if block.Typ == dataTypeLineBreak {
+		return true
+	}
+	return block.Is(dataTypeComment|dataTypeCommentLine) && hasLineBreakSuffix(block.Val)
+}
+
+// ownLineComment 判断第i个block是否为独占一行的注释。解析时成员所在行之后的注释可能被记录为同行注释，
+// 因此紧跟在换行之后的同行注释也视为独占一行
+func (n *Node) ownLineComment(i int) bool {
+	switch n.block[i].Typ {
+	case dataTypeComment:
+		return true
+	case dataTypeCommentLine:
+		return i > 0 && endsWithLineBreak(n.block[i-1])
+	}
+	return false
+}
+
+// leadingStart 将keyBlockRange返回的成员开始位置前移到紧跟在上一个成员的换行之后的行注释，
+// 这些注释独占一行，同样属于该成员的前置注释
+func (n *Node) leadingStart(start int) int {
+	for start > 0 && n.ownLineComment(start-1) {
+		start--
+	}
+	return start
+}
+
+// UndocumentedKeys 返回没有任何关联注释的key的路径，按文档顺序排列。
+// key之前的前置注释、key与值之间的注释、值之后的同行注释，以及对象/数组起始符号同行的注释均视为该key的说明
+func (n *Node) UndocumentedKeys() []string {
+	var paths []string
+	n.undocumentedKeys(nil, &paths)
+	return paths
+}
+
+func (n *Node) undocumentedKeys(path []string, paths *[]string) {
+	if n.parse().Error() != nil {
+		return
+	}
+	for _, key := range n.orderedKeys() {
+		childPath := append(path[:len(path):len(path)], key)
+		child := n.children[key]
+		if n.typ == Object {
+			leading, inner, trailing := n.keyComments(key)
+			if len(leading)+len(inner)+len(trailing) == 0 && !child.hasStartComment() {
+				*paths = append(*paths, joinPath(childPath))
+			}
+		}
+		child.undocumentedKeys(childPath, paths)
+	}
+}
+
+// hasStartComment 判断对象/数组的起始符号之后是否紧跟同行注释，如 "key": { // 说明
+func (n *Node) hasStartComment() bool {
+	if n.parse().Error() != nil || (n.typ != Object && n.typ != Array) {
+		return false
+	}
+	started := false
+	for _, block := range n.block {
+		switch {
+		case block.Typ == dataTypeStartFlag:
+			started = true
+		case !started:
+		case block.Typ == dataTypeCommentLine:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// IndexComment 返回数组第i个元素关联的注释，按文档顺序排列，行注释不包含结尾的换行符。
+// 元素之前的注释与元素之后到行尾的注释（包括逗号之后的同行注释）均属于该元素，
+// 位于两个元素之间且没有换行的注释属于后一个元素。节点不是数组、下标越界或元素没有注释时返回nil
+func (n *Node) IndexComment(i int) []string {
+	if n.parse().Error() != nil || n.typ != Array {
+		return nil
+	}
+	var comments []string
+	for _, block := range n.elementComments(strconv.Itoa(i)) {
+		comments = append(comments, strings.TrimRight(block.Val, "\r\n"))
+	}
+	return comments
+}
+
+// elementComments 返回数组中下标为key的元素关联的注释block
+func (n *Node) elementComments(key string) []dataBlock {
+	var prev string // 上一个元素的下标，起始符号之后为空
+	var before, after []dataBlock
+	seenBreak := false
+	var result []dataBlock
+	for _, block := range n.block {
+		switch {
+		case block.Is(dataTypeComment | dataTypeCommentLine):
+			if seenBreak {
+				after = append(after, block)
+			} else {
+				before = append(before, block)
+			}
+			seenBreak = seenBreak || endsWithLineBreak(block)
+		case block.Typ == dataTypeLineBreak:
+			seenBreak = true
+		case block.Typ == dataTypeVal || block.Typ == dataTypeEndFlag:
+			// before 为上一个元素同行的注释，after 为换行之后的注释；没有换行时两个元素之间的注释属于后一个元素
+			if !seenBreak && block.Typ == dataTypeVal {
+				before, after = nil, before
+			}
+			if prev == key && prev != "" {
+				return append(result, before...)
+			}
+			if block.Typ == dataTypeVal && block.Val == key {
+				result = append(result, after...)
+			}
+			if block.Typ == dataTypeVal {
+				prev = block.Val
+			}
+			before, after, seenBreak = nil, nil, false
+		}
+	}
+	return result
+}
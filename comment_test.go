@@ -0,0 +1,163 @@
+package pjson5
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNode_PrependComment(t *testing.T) {
+	node := New(rawJson).PrependComment("AUTOGENERATED — DO NOT EDIT\n\nsource: rawJson")
+	if node.Error() != nil {
+		t.Fatal("prepend comment error:", node.Error())
+	}
+	pretty := node.Pretty()
+	wantHeader := "// AUTOGENERATED — DO NOT EDIT\n//\n// source: rawJson\n{ // 首行注释\n"
+	if !strings.HasPrefix(pretty, wantHeader) {
+		t.Fatalf("unexpected header:\n%s", pretty)
+	}
+	reparsed := New(pretty)
+	if v := reparsed.Get("map_key.val").Value(); v != "60000" {
+		t.Fatalf("expected map_key.val=60000 after reparse, got %q (err=%v)", v, reparsed.Error())
+	}
+}
+
+func TestNode_PrependCommentShebang(t *testing.T) {
+	opts := ParseOptions{AllowShebang: true}
+	node := NewWithOptions("#!/bin/app\n{a: 1}", opts).PrependComment("generated")
+	want := "#!/bin/app\n// generated\n{   a: 1}"
+	if got := node.Pretty(); got != want {
+		t.Fatalf("PrependComment() = %q, want %q", got, want)
+	}
+	reparsed := NewWithOptions(node.Pretty(), opts)
+	if reparsed.Get("a").Value() != "1" || reparsed.Pretty() != want {
+		t.Fatalf("reparse = %q (err=%v), want %q", reparsed.Pretty(), reparsed.Error(), want)
+	}
+}
+
+func TestNode_TrailingComments(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "object", raw: rawJson, want: []string{"// 尾行注释", "// 末尾注释"}},
+		{name: "array", raw: "// head\n[1, /* in */ 2] /* tail */\n// sign", want: []string{"/* tail */", "// sign"}},
+		{name: "scalar", raw: "1 // one", want: []string{"// one"}},
+		{name: "none", raw: `{"a": 1}`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.raw).TrailingComments()
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Fatalf("TrailingComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode_ForEachComment(t *testing.T) {
+	node := New(rawArrayJson)
+	var visited []string
+	node.ForEachComment(func(text string, line bool) string {
+		visited = append(visited, text)
+		if line {
+			return "// " + strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(text, "//")))
+		}
+		return text
+	})
+	if node.Error() != nil {
+		t.Fatal("for each comment error:", node.Error())
+	}
+	want := []string{"//test", "// 行注释", "/*这是一段注释*/", "/* 块注释 */", "// test", "// 尾部行注释", "/* 尾部块注释 \n*/"}
+	if strings.Join(visited, "|") != strings.Join(want, "|") {
+		t.Fatalf("visited comments = %q, want %q", visited, want)
+	}
+	pretty := node.Pretty()
+	for _, comment := range []string{"// TEST\n", "// 尾部行注释\n", "/*这是一段注释*/"} {
+		if !strings.Contains(pretty, comment) {
+			t.Fatalf("expected %q in output:\n%s", comment, pretty)
+		}
+	}
+	if v := New(pretty).Get("multiline.1").Value(); v != "20" {
+		t.Fatalf("expected output to stay valid, got %q\n%s", v, pretty)
+	}
+}
+
+func TestCommentText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "line", raw: "// 人数\n", want: "人数"},
+		{name: "line_no_space", raw: "//test", want: "test"},
+		{name: "block_inline", raw: "/*key中注释*/", want: "key中注释"},
+		{name: "block_multi", raw: "/* multi\n * line\n */", want: "multi\nline"},
+		{name: "javadoc", raw: "/**\n   * first\n   *\n   * second\n   */\n", want: "first\n\nsecond"},
+		{name: "block_plain", raw: "/* 尾部块注释 \n*/", want: "尾部块注释"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommentText(tt.raw); got != tt.want {
+				t.Fatalf("CommentText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode_UndocumentedKeys(t *testing.T) {
+	raw := `{
+  // 名称
+  "name": "x",
+  "port": 8080, // 端口
+  "host": "localhost",
+  "tls": /* 证书 */ {
+    "cert": "a.pem",
+  },
+  "limits": { // 限流
+    "qps": 100,
+  },
+  "plain": {
+    "enabled": true, // 开关
+  },
+}`
+	got := New(raw).UndocumentedKeys()
+	want := []string{"host", "tls.cert", "limits.qps", "plain"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UndocumentedKeys() = %v, want %v", got, want)
+	}
+	if got := New(rawJson).UndocumentedKeys(); len(got) != 0 {
+		t.Fatalf("expected all keys documented, got %v", got)
+	}
+}
+
+func TestNode_IndexComment(t *testing.T) {
+	data := `[ // 列表说明
+  1, // primary
+  // 备用
+  2 // secondary
+  , 3, /* four */ 4,
+  /* 末尾 */
+  5 /* five */]`
+	node := New(data)
+	tests := []struct {
+		index int
+		want  []string
+	}{
+		{index: 0, want: []string{"// primary"}},
+		{index: 1, want: []string{"// 备用", "// secondary"}},
+		{index: 2, want: nil},
+		{index: 3, want: []string{"/* four */"}},
+		{index: 4, want: []string{"/* 末尾 */", "/* five */"}},
+		{index: 5, want: nil},
+	}
+	for _, tt := range tests {
+		if got := node.IndexComment(tt.index); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("IndexComment(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+	if got := New(rawJson).IndexComment(0); got != nil {
+		t.Fatalf("IndexComment() on object = %q, want nil", got)
+	}
+}
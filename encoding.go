@@ -0,0 +1,72 @@
+package pjson5
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+	"golang.org/x/text/transform"
+)
+
+// NewEncoded 将enc编码的data转换为UTF-8后创建节点，解析仍然是懒解析的。enc为nil时按BOM识别
+// UTF-32LE/BE、UTF-16LE/BE，识别出的BOM会被去掉，没有BOM时视为UTF-8原样使用。
+// data中存在所用编码下非法的字节序列时返回 ErrInvalidEncoding，错误信息中包含其在data中的字节偏移
+func NewEncoded(data []byte, enc encoding.Encoding) (*Node, error) {
+	start := 0
+	if enc == nil {
+		if enc, start = detectEncoding(data); enc == nil {
+			return New(string(data)), nil
+		}
+	}
+	decoded, err := decodeStrict(data, start, enc)
+	if err != nil {
+		return nil, err
+	}
+	return New(string(decoded)), nil
+}
+
+// detectEncoding 按BOM识别输入的编码，返回不再处理BOM的编码及BOM的长度，没有UTF-16/UTF-32的BOM时返回nil
+func detectEncoding(data []byte) (encoding.Encoding, int) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x00, 0x00, 0xfe, 0xff}):
+		return utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM), 4
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe, 0x00, 0x00}): // 需要先于UTF-16LE判断
+		return utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM), 4
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), 2
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), 2
+	}
+	return nil, 0
+}
+
+// decodeStrict 使用enc将data[start:]转换为UTF-8。解码器会将非法的字节序列替换为U+FFFD，
+// 因此逐个字符解码，输出U+FFFD而原文并不是U+FFFD的编码时报告该位置
+func decodeStrict(data []byte, start int, enc encoding.Encoding) ([]byte, error) {
+	runeError := []byte(string(utf8.RuneError))
+	// 编码器可能在开头输出BOM，取两次编码结果的差作为U+FFFD本身的编码
+	prefix, _ := enc.NewEncoder().Bytes([]byte("a"))
+	replacement, _ := enc.NewEncoder().Bytes(append([]byte("a"), runeError...))
+	replacement = replacement[min(len(prefix), len(replacement)):]
+	dec := enc.NewDecoder()
+	out := make([]byte, 0, len(data)-start)
+	dst := make([]byte, utf8.UTFMax)
+	for pos := start; pos < len(data); {
+		// 输出空间只容纳一个U+FFFD，使替换字符单独输出，空间不足时再尝试容纳一个4字节的字符
+		nDst, nSrc, err := dec.Transform(dst[:len(runeError)], data[pos:], true)
+		if nDst == 0 && errors.Is(err, transform.ErrShortDst) {
+			nDst, nSrc, err = dec.Transform(dst, data[pos:], true)
+		}
+		invalid := bytes.Equal(dst[:nDst], runeError) && !bytes.Equal(data[pos:pos+nSrc], replacement)
+		if invalid || err != nil && !errors.Is(err, transform.ErrShortDst) || nSrc == 0 && nDst == 0 {
+			return nil, fmt.Errorf("%w at position %d", ErrInvalidEncoding, pos)
+		}
+		out = append(out, dst[:nDst]...)
+		pos += nSrc
+	}
+	return out, nil
+}
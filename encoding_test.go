@@ -0,0 +1,76 @@
+package pjson5
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+func TestNewEncoded(t *testing.T) {
+	const doc = "{\n  // 注释\n  name: '\u00e9\U0001f600', // 尾\n}"
+	encode := func(enc encoding.Encoding, s string) []byte {
+		data, err := enc.NewEncoder().Bytes([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	tests := []struct {
+		name string
+		data []byte
+		enc  encoding.Encoding
+	}{
+		{name: "utf8", data: []byte(doc)},
+		{name: "utf16le_bom", data: encode(unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), doc)},
+		{name: "utf16be_bom", data: encode(unicode.UTF16(unicode.BigEndian, unicode.UseBOM), doc)},
+		{name: "utf32le_bom", data: encode(utf32.UTF32(utf32.LittleEndian, utf32.UseBOM), doc)},
+		{name: "utf32be_bom", data: encode(utf32.UTF32(utf32.BigEndian, utf32.UseBOM), doc)},
+		{name: "utf16le_declared", data: encode(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), doc),
+			enc: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+		{name: "latin1_declared", data: encode(charmap.ISO8859_1, "{a: '\u00e9'}"), enc: charmap.ISO8859_1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := NewEncoded(tt.data, tt.enc)
+			if err != nil {
+				t.Fatal("NewEncoded() error:", err)
+			}
+			want := New(doc).Pretty()
+			if tt.name == "latin1_declared" {
+				want = "{a: '\u00e9'}"
+			}
+			if got := node.Pretty(); got != want {
+				t.Fatalf("NewEncoded() = %q, want %q", got, want)
+			}
+		})
+	}
+
+	errTests := []struct {
+		name string
+		data []byte
+		enc  encoding.Encoding
+		want string
+	}{
+		// 未配对的代理项 0xD800
+		{name: "unpaired_surrogate", data: []byte{0xff, 0xfe, '1', 0, 0x00, 0xd8, '2', 0}, want: "at position 4"},
+		{name: "odd_length", data: []byte{0xfe, 0xff, 0, '1', 0}, want: "at position 4"},
+		{name: "utf32_out_of_range", data: []byte{0, 0, 0xfe, 0xff, 0, 0x11, 0, 0}, want: "at position 4"},
+		{name: "declared_utf8", data: []byte("{a: '\xff'}"), enc: unicode.UTF8, want: "at position 5"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEncoded(tt.data, tt.enc)
+			if !errors.Is(err, ErrInvalidEncoding) || err.Error() != ErrInvalidEncoding.Error()+" "+tt.want {
+				t.Fatalf("NewEncoded() error = %v, want ErrInvalidEncoding %s", err, tt.want)
+			}
+		})
+	}
+	// 原文中合法的U+FFFD不视为错误
+	if node, err := NewEncoded(encode(unicode.UTF16(unicode.BigEndian, unicode.UseBOM), "'\ufffd'"), nil); err != nil || node.Value() != "'\ufffd'" {
+		t.Fatalf("NewEncoded() with U+FFFD = %v, %v", node, err)
+	}
+}
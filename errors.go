@@ -0,0 +1,121 @@
+package pjson5
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnexpectedEOF 输入在对象、数组、字符串或注释结束前终止，表示输入不完整而非格式错误
+var ErrUnexpectedEOF = errors.New("unexpected end of JSON5 input")
+
+// ErrCommentTooLong 注释的长度超过 ParseOptions.MaxCommentLength
+var ErrCommentTooLong = errors.New("JSON5 comment too long")
+
+// ErrStringTooLong 字符串的长度超过 ParseOptions.MaxStringLength
+var ErrStringTooLong = errors.New("JSON5 string too long")
+
+// ErrInvalidUTF8 输入中包含非法的UTF-8字节序列
+var ErrInvalidUTF8 = errors.New("invalid UTF-8 in JSON5 input")
+
+// ErrInvalidEncoding NewEncoded 的输入中包含所用编码下非法的字节序列
+var ErrInvalidEncoding = errors.New("invalid byte sequence for JSON5 input encoding")
+
+// ParseError 描述一个解析错误
+type ParseError struct {
+	Offset int    // 出错位置在原始文档中的字节偏移
+	Line   int    // 出错位置所在的行，从1开始
+	Column int    // 出错位置所在的列，从1开始，按字符计算
+	Msg    string // 错误描述
+	Err    error  // 底层错误，如 ErrUnexpectedEOF
+}
+
+func (e *ParseError) Error() string {
+	return e.Msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// MissingKeysError 由 Require 返回，包含全部缺失的路径
+type MissingKeysError struct {
+	Paths []string // 缺失的路径，与传入的顺序一致
+}
+
+func (e *MissingKeysError) Error() string {
+	return "missing required keys: " + strings.Join(e.Paths, ", ")
+}
+
+// UnknownKeysError 由 RejectUnknownKeys 返回，包含全部不允许的key的路径
+type UnknownKeysError struct {
+	Paths []string // 不允许的key的路径，按文档顺序排列
+}
+
+func (e *UnknownKeysError) Error() string {
+	return "unknown keys: " + strings.Join(e.Paths, ", ")
+}
+
+// Errors 解析整棵树并按文档顺序返回遇到的解析错误。
+// 开启 CollectErrors 时会跳过可恢复的错误继续解析并返回全部错误，否则最多返回第一个错误
+func (n *Node) Errors() []ParseError {
+	var errs []ParseError
+	n.collectErrors(&errs)
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Offset < errs[j].Offset
+	})
+	return errs
+}
+
+// collectErrors 递归收集解析错误，非 CollectErrors 模式下遇到错误时返回 false 停止收集
+func (n *Node) collectErrors(errs *[]ParseError) bool {
+	n.parse()
+	if n.options().CollectErrors {
+		*errs = append(*errs, n.errs...)
+	} else if pe, ok := n.err.(*ParseError); ok {
+		*errs = append(*errs, *pe)
+		return false
+	}
+	visited := make(map[*Node]bool, len(n.children))
+	for _, child := range n.orderedChildren() {
+		if visited[child] {
+			continue
+		}
+		visited[child] = true
+		if !child.collectErrors(errs) {
+			return false
+		}
+	}
+	return true
+}
+
+// ShadowedKeys 返回开启 AllowDuplicateKeys 时被后出现的同名key覆盖的key路径，按节点的遍历顺序排列，
+// 同一个key被覆盖多次时出现多次
+func (n *Node) ShadowedKeys() []string {
+	var keys []string
+	n.walk(nil, func(path []string, node *Node) bool {
+		if node.parse().Error() != nil {
+			return false
+		}
+		for _, key := range node.shadowed {
+			keys = append(keys, joinPath(append(path[:len(path):len(path)], key)))
+		}
+		return true
+	})
+	return keys
+}
+
+// ValidateUTF8 检查节点的原始输入是否为合法的UTF-8，返回第一个非法字节序列处的 ParseError，
+// 错误可通过 errors.Is(err, ErrInvalidUTF8) 识别。只检查编码而不解析，也不影响节点的解析状态
+func (n *Node) ValidateUTF8() error {
+	for i := 0; i < len(n.raw); {
+		r, size := utf8.DecodeRuneInString(n.raw[i:])
+		if r == utf8.RuneError && size == 1 {
+			return n.newParseError(i, fmt.Sprintf("invalid UTF-8 byte 0x%02x at position %d", n.raw[i], n.offset+i), ErrInvalidUTF8)
+		}
+		i += size
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package pjson5
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNode_Errors(t *testing.T) {
+	input := `{
+  "a": tru,
+  "b": 1,
+  "b": 2,
+  "c": [1, @, 3],
+  "d": {"x": nul},
+  "e": "ok",
+}`
+	t.Run("collect_all", func(t *testing.T) {
+		node := NewWithOptions(input, ParseOptions{CollectErrors: true})
+		errs := node.Errors()
+		wantOffsets := []int{
+			strings.Index(input, "tru"),
+			strings.LastIndex(input, `"b"`),
+			strings.Index(input, "@"),
+			strings.Index(input, "nul"),
+		}
+		if len(errs) != len(wantOffsets) {
+			t.Fatalf("expected %d errors, got %d: %v", len(wantOffsets), len(errs), errs)
+		}
+		for i, want := range wantOffsets {
+			if errs[i].Offset != want {
+				t.Fatalf("error %d: expected offset %d, got %d (%s)", i, want, errs[i].Offset, errs[i].Msg)
+			}
+		}
+		if !strings.HasPrefix(errs[1].Msg, "repeat key") {
+			t.Fatalf("expected repeat key error, got %q", errs[1].Msg)
+		}
+		if node.Error() == nil || node.Error().Error() != errs[0].Msg {
+			t.Fatalf("expected Error() to report the first error, got %v", node.Error())
+		}
+	})
+
+	t.Run("fail_fast", func(t *testing.T) {
+		node := New(input)
+		errs := node.Errors()
+		if len(errs) != 1 || errs[0].Offset != strings.Index(input, "tru") {
+			t.Fatalf("expected only the first error, got %v", errs)
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		if errs := NewWithOptions(rawJson, ParseOptions{CollectErrors: true}).Errors(); len(errs) != 0 {
+			t.Fatalf("expected no errors, got %v", errs)
+		}
+		if errs := New(rawArrayJson).Errors(); len(errs) != 0 {
+			t.Fatalf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("unterminated_object", func(t *testing.T) {
+		for _, raw := range []string{`{"a": 1`, `{"a": 1  `, `{"a": {"b": 1}`} {
+			if err := New(raw).Parse().Error(); err == nil {
+				t.Fatalf("expected error for %q", raw)
+			}
+		}
+	})
+}
+
+func TestNode_UnexpectedEOF(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		isEOF bool
+	}{
+		{name: "object", raw: `{"a": 1`, isEOF: true},
+		{name: "nested", raw: `{"a": {"b": [1, 2`, isEOF: true},
+		{name: "string", raw: `{"a": "abc`, isEOF: true},
+		{name: "escaped_string", raw: `{"a": "a\"b`, isEOF: true},
+		{name: "block_comment", raw: `{"a": 1 /* c`, isEOF: true},
+		{name: "empty", raw: "  ", isEOF: true},
+		{name: "invalid", raw: `{"a": @}`, isEOF: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.raw).Parse()
+			if node.Error() == nil {
+				t.Fatal("expected parse error")
+			}
+			if errors.Is(node.Error(), ErrUnexpectedEOF) != tt.isEOF || node.NeedsMoreInput() != tt.isEOF {
+				t.Fatalf("expected EOF=%v, got %v", tt.isEOF, node.Error())
+			}
+		})
+	}
+}
+
+func TestNode_AllowPartial(t *testing.T) {
+	opts := ParseOptions{AllowPartial: true}
+	node := NewWithOptions(`{
+  "done": 1, // 完整的成员
+  "list": [1, 2`, opts)
+	if node.Parse().Error() != nil {
+		t.Fatal("expected no error in partial mode:", node.Error())
+	}
+	if !node.NeedsMoreInput() {
+		t.Fatal("expected node to need more input")
+	}
+	if v := node.Get("done").Value(); v != "1" {
+		t.Fatalf("expected complete member to be accessible, got %q", v)
+	}
+	if n := node.Get("list").Len(); n != 2 {
+		t.Fatalf("expected partial array with 2 elements, got %d", n)
+	}
+
+	for _, tt := range []struct{ raw, absent string }{
+		{raw: `{"a": 1, "b": "unterminated`, absent: "b"},
+		{raw: `{"a": 1, "b":`, absent: "b"},
+		{raw: `{"a": 1, "b`, absent: "b"},
+	} {
+		partial := NewWithOptions(tt.raw, opts)
+		if partial.Parse().Error() != nil || !partial.NeedsMoreInput() {
+			t.Fatalf("%s: expected incomplete document, err=%v", tt.raw, partial.Error())
+		}
+		if partial.Get("a").Value() != "1" || partial.Exists(tt.absent) {
+			t.Fatalf("%s: expected only complete members", tt.raw)
+		}
+	}
+
+	node.Resume(", 3],\n  \"name\": \"x\"\n}")
+	if node.Error() != nil || node.NeedsMoreInput() {
+		t.Fatalf("expected complete document after resume, err=%v", node.Error())
+	}
+	if node.Get("list").Len() != 3 || node.Get("name").Value() != `"x"` {
+		t.Fatalf("unexpected resumed document:\n%s", node.Pretty())
+	}
+
+	invalid := NewWithOptions(`{"a": @`, opts)
+	if invalid.Parse().Error() == nil || invalid.NeedsMoreInput() {
+		t.Fatal("expected invalid input to stay an error in partial mode")
+	}
+}
+
+func TestNode_ShadowedKeys(t *testing.T) {
+	input := `{
+  "a": 1,
+  // 第一个b
+  "b": 1, // 旧值
+  "c": {"x": 1, /* 第一个x */ "x": 2, "x": 3},
+  "b": 2, // 第二个b
+  "list": [{"k": 1, "k": 2}],
+}`
+	node := NewWithOptions(input, ParseOptions{AllowDuplicateKeys: true})
+	if node.Parse().Error() != nil {
+		t.Fatal("expected duplicate keys to be allowed:", node.Error())
+	}
+	want := []string{"b", "c.x", "c.x", "list.0.k"}
+	if got := node.ShadowedKeys(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("ShadowedKeys() = %q, want %q", got, want)
+	}
+	for path, val := range map[string]string{"b": "2", "c.x": "3", "list.0.k": "2", "a": "1"} {
+		if v := node.Get(path).Value(); v != val {
+			t.Fatalf("expected %s=%s, got %q", path, val, v)
+		}
+	}
+	pretty := node.Pretty()
+	// 被覆盖的成员不再输出，注释保留在原位置：同一行的注释改为独占一行
+	want = []string{"\"a\": 1, \n// 第一个b\n  // 旧值\n  \"c\"", "/* 第一个x */"}
+	for _, w := range want {
+		if strings.Count(pretty, `"b"`) != 1 || !strings.Contains(pretty, w) {
+			t.Fatalf("expected %q in output without the shadowed members:\n%s", w, pretty)
+		}
+	}
+	if reparsed := New(pretty); reparsed.Get("b").Value() != "2" {
+		t.Fatalf("expected output without duplicates, err=%v\n%s", reparsed.Error(), pretty)
+	}
+	if New(input).Parse().Error() == nil {
+		t.Fatal("expected duplicate keys to be rejected by default")
+	}
+}
+
+func TestParseError_LineColumn(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		path      string
+		line, col int
+	}{
+		{name: "lf", raw: "{\n  \"a\": 1,\n  \"b\": tru\n}", line: 3, col: 8},
+		{name: "crlf", raw: "{\r\n  \"a\": 1,\r\n  \"b\": tru\r\n}", line: 3, col: 8},
+		{name: "line_separator", raw: "{\u2028  \"a\": 1,\u2029  \"b\": tru\u2028}", line: 3, col: 8},
+		{name: "multibyte_column", raw: "{\"名字\": \"值\", \"b\": tru}", line: 1, col: 18},
+		{name: "nested", raw: "{\n  \"a\": {\n    \"x\": [1,\n      @]}\n}", path: "a.x", line: 4, col: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.raw).Parse()
+			node.Get(tt.path)
+			var pe *ParseError
+			if !errors.As(node.Error(), &pe) {
+				t.Fatalf("expected ParseError, got %v", node.Error())
+			}
+			if pe.Line != tt.line || pe.Column != tt.col {
+				t.Fatalf("expected %d:%d, got %d:%d (%s)", tt.line, tt.col, pe.Line, pe.Column, pe.Msg)
+			}
+		})
+	}
+}
+
+func TestNode_LineCommentTerminators(t *testing.T) {
+	for _, sep := range []string{"\u2028", "\u2029"} {
+		node := New("{\n  \"a\": 1, // 注释" + sep + "  \"b\": 2,\n}")
+		if v := node.Get("b").Value(); v != "2" {
+			t.Fatalf("expected line comment to end at %U, got b=%q err=%v", []rune(sep)[0], v, node.Error())
+		}
+		if comments := New("1 // c" + sep + "// d").TrailingComments(); len(comments) != 2 {
+			t.Fatalf("expected 2 trailing comments, got %q", comments)
+		}
+	}
+}
+
+func TestNode_ValidateUTF8(t *testing.T) {
+	if err := New(rawJson).ValidateUTF8(); err != nil {
+		t.Fatalf("ValidateUTF8() = %v, want nil", err)
+	}
+	data := "{\n  \"名\": \"ok\",\n  \"b\": \"x\xff\"\n}"
+	err := New(data).ValidateUTF8()
+	var pe *ParseError
+	if !errors.Is(err, ErrInvalidUTF8) || !errors.As(err, &pe) {
+		t.Fatalf("ValidateUTF8() = %v, want ErrInvalidUTF8", err)
+	}
+	if pe.Offset != strings.Index(data, "\xff") || pe.Line != 3 || pe.Column != 10 {
+		t.Fatalf("unexpected position: offset=%d line=%d column=%d", pe.Offset, pe.Line, pe.Column)
+	}
+	// 截断的多字节字符
+	if err := New("\"\xe4\xb8\"").ValidateUTF8(); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("ValidateUTF8() = %v, want ErrInvalidUTF8 for truncated sequence", err)
+	}
+	// 子节点报告在原始文档中的位置
+	node := New(data)
+	if err := node.Get("b").ValidateUTF8(); !errors.As(err, &pe) || pe.Offset != strings.Index(data, "\xff") {
+		t.Fatalf("ValidateUTF8() on child = %v", err)
+	}
+}
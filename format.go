@@ -0,0 +1,157 @@
+package pjson5
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// inlineObjectData 判断从startIdx处开始的对象是否满足单行输出的条件，满足时返回单行内容及结束符所在位置
+func inlineObjectData(node *Node, startIdx int, opts *Options) (string, int, bool) {
+	if opts.InlineObjectWidth <= 0 || node.typ != Object {
+		return "", 0, false
+	}
+	endIdx := startIdx + 1
+	for endIdx < len(node.block) && node.block[endIdx].Typ != dataTypeEndFlag {
+		endIdx++
+	}
+	if endIdx >= len(node.block) {
+		return "", 0, false
+	}
+	inline, ok := inlineData(node, opts)
+	if !ok || utf8.RuneCountInString(inline) >= opts.InlineObjectWidth {
+		return "", 0, false
+	}
+	return inline, endIdx, true
+}
+
+// inlineData 将Object/Array渲染为单行形式，包含注释时返回false
+func inlineData(node *Node, opts *Options) (string, bool) {
+	if node.parse().Error() != nil {
+		return "", false
+	}
+	if node.typ != Object && node.typ != Array {
+		if opts.ThousandsSeparator && node.typ == Number {
+			return groupThousands(node.val), true
+		}
+		return node.val, true
+	}
+	members := make([]string, 0, len(node.children))
+	started := false
+	preKey := ""
+	for _, block := range node.block {
+		switch block.Typ {
+		case dataTypeStartFlag:
+			started = true
+		case dataTypeEndFlag:
+			started = false
+		case dataTypeComment, dataTypeCommentLine:
+			if started {
+				return "", false
+			}
+		case dataTypeKey:
+			preKey = block.KeyUnQuot()
+			members = append(members, block.Val+string(colon)+string(space))
+		case dataTypeVal:
+			child := node.children[block.Val]
+			if node.typ == Object {
+				child = node.children[preKey]
+			}
+			if child == nil {
+				return "", false
+			}
+			val, ok := inlineData(child, opts)
+			if !ok {
+				return "", false
+			}
+			if node.typ == Object {
+				members[len(members)-1] += val
+			} else {
+				members = append(members, val)
+			}
+		}
+	}
+	pair := objectPair
+	if node.typ == Array {
+		pair = arrayPair
+	}
+	if len(members) == 0 {
+		return opts.EmptyCollections.render(pair), true
+	}
+	sep := ", "
+	if node.typ == Object && !opts.NoBracketPadding {
+		return string(pair[0]) + " " + strings.Join(members, sep) + " " + string(pair[1]), true
+	}
+	return string(pair[0]) + strings.Join(members, sep) + string(pair[1]), true
+}
+
+// trimTrailingSpace 删除每一行末尾的空白。字符串中不会出现未转义的换行，因此不会修改字符串内容
+func trimTrailingSpace(s string) string {
+	lines := strings.Split(s, lineBreak)
+	for i, line := range lines {
+		cr := strings.HasSuffix(line, "\r")
+		line = strings.TrimRight(strings.TrimSuffix(line, "\r"), " \t")
+		if cr {
+			line += "\r"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, lineBreak)
+}
+
+// emptyBlocks 判断从startIdx处开始的集合是否为空（开始符与结束符之间只有换行），为空时返回结束符所在位置
+func emptyBlocks(node *Node, startIdx int) (int, bool) {
+	for idx := startIdx + 1; idx < len(node.block); idx++ {
+		switch node.block[idx].Typ {
+		case dataTypeEndFlag:
+			return idx, true
+		case dataTypeLineBreak:
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// emptyCollection 判断未解析的原文是否为空对象或空数组，返回其开始符与结束符
+func emptyCollection(raw string) ([2]byte, bool) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 2 || strings.TrimSpace(raw[1:len(raw)-1]) != "" {
+		return [2]byte{}, false
+	}
+	for _, pair := range [][2]byte{objectPair, arrayPair} {
+		if raw[0] == pair[0] && raw[len(raw)-1] == pair[1] {
+			return pair, true
+		}
+	}
+	return [2]byte{}, false
+}
+
+// groupThousands 在十进制数字的整数部分每三位插入逗号，十六进制、科学计数法等其他形式保持不变
+func groupThousands(literal string) string {
+	sign, rest := "", literal
+	if rest != "" && (rest[0] == '-' || rest[0] == '+') {
+		sign, rest = rest[:1], rest[1:]
+	}
+	intPart, fraction := rest, ""
+	if i := strings.IndexByte(rest, '.'); i >= 0 {
+		intPart, fraction = rest[:i], rest[i:]
+	}
+	if intPart == "" || strings.ContainsAny(fraction, "eE") {
+		return literal
+	}
+	for _, c := range intPart {
+		if c < '0' || c > '9' {
+			return literal
+		}
+	}
+	buf := &strings.Builder{}
+	buf.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			buf.WriteByte(comma)
+		}
+		buf.WriteRune(c)
+	}
+	buf.WriteString(fraction)
+	return buf.String()
+}
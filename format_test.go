@@ -0,0 +1,330 @@
+package pjson5
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNode_Format_InlineObjectWidth(t *testing.T) {
+	input := `{
+  "small": {
+    "a": 1
+  },
+  "pair": {
+    "x": [1, 2],
+    "y": "z"
+  },
+  "documented": {
+    // 注释会强制展开
+    "a": 1
+  }
+}`
+	node := New(input)
+	got := node.Format(Options{InlineObjectWidth: 20})
+	if !strings.Contains(got, `"small": { "a": 1 }`) {
+		t.Fatalf("expected small object to be inlined:\n%s", got)
+	}
+	if strings.Contains(got, `"pair": {"`) || strings.Contains(got, `"pair": { "x"`) {
+		t.Fatalf("expected object over the threshold to stay expanded:\n%s", got)
+	}
+	if !strings.Contains(got, "// 注释会强制展开\n    \"a\": 1") {
+		t.Fatalf("expected documented object to stay expanded:\n%s", got)
+	}
+	if wide := node.Format(Options{InlineObjectWidth: 40}); !strings.Contains(wide, `"pair": { "x": [1, 2], "y": "z" }`) {
+		t.Fatalf("expected pair object to be inlined with a wider threshold:\n%s", wide)
+	}
+	if node.Format(Options{}) != node.Pretty() {
+		t.Fatal("expected zero Options to match Pretty")
+	}
+	if reparsed := New(got); reparsed.Get("pair.y").Value() != `"z"` {
+		t.Fatalf("expected formatted output to stay valid, err=%v", reparsed.Error())
+	}
+}
+
+func TestNode_Format_PreserveColonSpacing(t *testing.T) {
+	input := `{
+  "tight":1,
+  "loose" : "a",
+  "tab":	true,
+  "commented": /* c */ null,
+  "wrapped":
+    2,
+  "nested" :{
+    "x":[1, 2]
+  }
+}`
+	node := New(input)
+	node.Get("nested")
+	node.Set("added", 3)
+	got := node.Format(Options{PreserveColonSpacing: true})
+	for _, want := range []string{`"tight":1,`, `"loose" : "a",`, "\"tab\":\ttrue,", `"commented": /* c */`, `"wrapped": 2,`, `"nested" :{`, `"x":[1, 2]`, `"added": 3`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+	if pretty := node.Pretty(); !strings.Contains(pretty, `"tight": 1,`) || !strings.Contains(pretty, `"loose": "a",`) {
+		t.Fatalf("expected default output to normalize colons:\n%s", pretty)
+	}
+}
+
+func TestNode_Format_NoBracketPadding(t *testing.T) {
+	node := New(`{
+  "point": {"x": 1, "y": 2},
+  "list": [1, [2, 3]],
+  "empty": {},
+  "multi": [
+    1,
+  ],
+}`)
+	for _, path := range []string{"point", "list.1", "empty", "multi"} {
+		node.Get(path)
+	}
+	padded := node.Pretty()
+	for _, want := range []string{"\"point\": {     \"x\": 1,\n    \"y\": 2  }", `"list": [ 1, [ 2, 3    ]  ]`, `"empty": {   }`} {
+		if !strings.Contains(padded, want) {
+			t.Fatalf("expected %q in default output:\n%s", want, padded)
+		}
+	}
+	got := node.Format(Options{NoBracketPadding: true})
+	for _, want := range []string{`"point": {"x": 1, "y": 2}`, `"list": [1, [2, 3]]`, `"empty": {}`, "\"multi\": [\n    1,", "\n  ]"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "{\n") {
+		t.Fatalf("expected multi-line root to stay expanded:\n%s", got)
+	}
+	if inline := node.Format(Options{NoBracketPadding: true, InlineObjectWidth: 40}); !strings.Contains(inline, `"point": {"x": 1, "y": 2}`) {
+		t.Fatalf("expected inlined object without padding:\n%s", inline)
+	}
+}
+
+func TestNode_Format_TrimTrailingSpace(t *testing.T) {
+	input := "{  \n  \"a\": 1,   // 注释   \n  \"b\": [\n    1,\t\n  ],\n  \"c\": \"x  \\\n  y\",\n  /* 块注释  \r\n   */\n}  "
+	node := New(input)
+	node.Get("b")
+	got := node.Format(Options{TrimTrailingSpace: true})
+	for i, line := range strings.Split(got, "\n") {
+		if strings.TrimRight(line, " \t") != line {
+			t.Fatalf("line %d has trailing whitespace: %q\n%s", i+1, line, got)
+		}
+	}
+	if !strings.Contains(got, "// 注释\n") || !strings.Contains(got, "\"x  \\\n  y\"") || !strings.Contains(got, "/* 块注释\r\n") {
+		t.Fatalf("unexpected output:\n%q", got)
+	}
+	if v := New(got).Get("b.0").Value(); v != "1" {
+		t.Fatalf("expected output to stay valid, got %q\n%s", v, got)
+	}
+	if !strings.Contains(node.Pretty(), "1, \n") {
+		t.Fatalf("expected default output to be unchanged:\n%q", node.Pretty())
+	}
+}
+
+func TestNode_Format_EmptyCollections(t *testing.T) {
+	node := New(`{
+  "raw_obj": {},
+  "raw_arr": [ ],
+  "parsed_obj": {},
+  "parsed_arr": [
+  ],
+  "list": [1],
+}`)
+	node.Get("parsed_obj")
+	node.Get("parsed_arr")
+	node.GetOrCreateArray("created")
+	node.Set("list.1", map[string]any{})
+	tests := []struct {
+		style    EmptyStyle
+		obj, arr string
+	}{
+		{style: EmptyCompact, obj: "{}", arr: "[]"},
+		{style: EmptySpaced, obj: "{ }", arr: "[ ]"},
+	}
+	for _, tt := range tests {
+		got := node.Format(Options{EmptyCollections: tt.style})
+		for _, want := range []string{
+			`"raw_obj": ` + tt.obj, `"raw_arr": ` + tt.arr, `"parsed_obj": ` + tt.obj,
+			`"parsed_arr": ` + tt.arr, `"created": ` + tt.arr, `[ 1, ` + tt.obj,
+		} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("style %d: expected %q in output:\n%s", tt.style, want, got)
+			}
+		}
+	}
+	if got := node.Format(Options{}); !strings.Contains(got, `"raw_arr": [ ]`) || !strings.Contains(got, "\"parsed_arr\": [\n  ]") {
+		t.Fatalf("expected default output to be unchanged:\n%s", got)
+	}
+}
+
+func TestNode_Format_ThousandsSeparator(t *testing.T) {
+	node := New(`{"pop": 1400000000, "neg": -12345.678, "small": 999, "exp": 12345e3, "hex": 0xFFFFFF, "list": [1000, 2.5], "s": "1000000"}`)
+	want := "{   \"pop\": 1,400,000,000,\n  \"neg\": -12,345.678,\n  \"small\": 999,\n  \"exp\": 12345e3,\n  \"hex\": 0xFFFFFF,\n  \"list\": [ 1,000, 2.5  ],\n  \"s\": \"1000000\"}"
+	if got := node.Format(Options{ThousandsSeparator: true}); got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+	if got := node.Format(Options{ThousandsSeparator: true, InlineObjectWidth: 100}); !strings.Contains(got, `"pop": 1,400,000,000`) {
+		t.Fatalf("Format() inline = %q", got)
+	}
+	// 仅影响输出，不修改节点
+	if got := node.Get("pop").Value(); got != "1400000000" {
+		t.Fatalf("expected value to be kept, got %q", got)
+	}
+	if got := New("1234567").Format(Options{ThousandsSeparator: true}); got != "1,234,567" {
+		t.Fatalf("Format() root number = %q", got)
+	}
+}
+
+func TestNode_Format_CommentColumn(t *testing.T) {
+	node := New(rawJson)
+	node.Get("map_key.name")
+	got := node.Format(Options{CommentColumn: 30})
+	want := "{                             // 首行注释\n  \"number_key\": 2,            // 人数\n  \"string_key\": /*key中注释*/\"www.com\", // 字符串类型后注释\n  \"array_key\": [1, 2, 3, 4],  // 数组类型\n  // 字典类型行注释\n  \"map_key\": {\n    // 字典类型首行注释\n    \"name\": \"This is name\",   // 字典字符串\n    \"val\": 60000,             // val\n    // array\n    \"data_list\": [5000], \n  }, \n}                             // 尾行注释\n// 末尾注释\n"
+	if got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+	if got := New(got).Format(Options{CommentColumn: 30}); got != want {
+		t.Fatalf("Format() is not stable: %q", got)
+	}
+	// 超过对齐列的行与注释间隔一个空格，值之间的块注释不受影响
+	got = New("[1 /* one */, 2] // 列表").Parse().Format(Options{CommentColumn: 4})
+	if want = "[ 1 /* one */, 2] // 列表"; got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestNode_RoundTripStable(t *testing.T) {
+	docs := []string{
+		rawJson,
+		rawArrayJson,
+		"{a: 1, /* x */ b: 2}",
+		"{\r\n a: 1,\r\n}",
+		"[1, // c\n 2]",
+		"{a: /* m\n l */ 1}",
+		"{\n\t\"a\": {\n\t\t\"b\": [\n\t\t\t1\n\t\t]\n\t}\n}",
+		"[[], {}, [ ], { }]",
+		"// a\n\n// b\n1 // c\n",
+	}
+	for _, doc := range docs {
+		node := New(doc).Parse()
+		before := node.Pretty()
+		if !node.RoundTripStable() {
+			t.Fatalf("RoundTripStable(%q) = false", doc)
+		}
+		if node.Pretty() != before {
+			t.Fatalf("RoundTripStable(%q) modified the node", doc)
+		}
+	}
+	for _, doc := range []string{`{"a": tru}`, `{"a": [1, 2}`} {
+		if New(doc).RoundTripStable() {
+			t.Fatalf("RoundTripStable(%q) = true, want false for invalid input", doc)
+		}
+	}
+}
+
+func TestPretty_CommentAfterStartFlag(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: "{ // c\n  \"a\": 1\n}", want: "{ // c\n  \"a\": 1\n}"},
+		{data: "{// c\n\"a\": 1}", want: "{ // c\n  \"a\": 1}"},
+		{data: "[ // c\n  1, \n  2\n]", want: "[ // c\n  1, \n  2\n]"},
+		{data: "{\n  \"m\": { // inner\n    \"a\": 1\n  }\n}", want: "{\n  \"m\": { // inner\n    \"a\": 1\n  }\n}"},
+		{data: `{ /* c */   "a": 1}`, want: `{ /* c */   "a": 1}`},
+		{data: "{ /* x */ // c\n  \"a\": 1}", want: "{ /* x */ // c\n  \"a\": 1}"},
+		{data: "{ // c\n}", want: "{ // c\n}"},
+	}
+	for _, tt := range tests {
+		node := New(tt.data)
+		node.parseTree()
+		if got := node.Pretty(); got != tt.want {
+			t.Fatalf("Pretty(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+	// 示例文档的首行在修改后重新格式化仍保持在同一行
+	node := New(rawJson).Set("number_key", 3)
+	node.parseTree()
+	if first, _, _ := strings.Cut(node.Pretty(), lineBreak); first != "{ // 首行注释" {
+		t.Fatalf("first line = %q", first)
+	}
+}
+
+func TestPretty_CommentAfterEndFlag(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: "[\n  1, \n  2\n] // end of list\n", want: "[\n  1, \n  2\n] // end of list\n"},
+		{data: "{\n  \"a\": [\n    1\n  ], // end\n  \"b\": [\n    2\n  ] // tail\n}", want: "{\n  \"a\": [\n    1\n  ], // end\n  \"b\": [\n    2\n  ] // tail\n}"},
+		{data: "[\n  [\n    1\n  ] /* inner */, \n  2\n]", want: "[\n  [\n    1\n  ] /* inner */,\n  2\n]"},
+		{data: "[1, 2]// end", want: "[ 1, 2] // end"},
+		{data: "{\n  \"a\": 1 // c\n}", want: "{\n  \"a\": 1 // c\n}"},
+	}
+	for _, tt := range tests {
+		node := New(tt.data)
+		node.parseTree()
+		got := node.Pretty()
+		if got != tt.want {
+			t.Fatalf("Pretty(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+		if again := New(got); again.parseTree() && again.Pretty() != got {
+			t.Fatalf("Pretty() is not stable for %q: %q", tt.data, again.Pretty())
+		}
+	}
+}
+
+func TestNode_WithOptions(t *testing.T) {
+	data := "{\n  \"a\": {\n    \"b\": 1000\n  }\n}"
+	if got := New(data).Pretty(); got != data {
+		t.Fatalf("Pretty() = %q, want unchanged default output", got)
+	}
+	opts := Options{ThousandsSeparator: true}
+	node := New(data).WithOptions(opts)
+	want := New(data).Format(opts)
+	if got := node.Pretty(); got != want || got == data {
+		t.Fatalf("Pretty() with node options = %q, want %q", got, want)
+	}
+	if got := node.Format(Options{}); got != data {
+		t.Fatalf("Format() should ignore node options, got %q", got)
+	}
+
+	SetDefaultOptions(Options{ThousandsSeparator: true})
+	t.Cleanup(func() { SetDefaultOptions(Options{}) })
+	if got := New(data).Pretty(); !strings.Contains(got, "1,000") {
+		t.Fatalf("Pretty() with package default = %q", got)
+	}
+	if got := New(data).WithOptions(Options{}).Pretty(); got != data {
+		t.Fatalf("node options should override package default, got %q", got)
+	}
+	if !New(data).RoundTripStable() {
+		t.Fatal("expected RoundTripStable() to ignore the display-only ThousandsSeparator default")
+	}
+	// 子节点继承父节点的格式选项，无论在 WithOptions 之前还是之后解析
+	parsed := New(data)
+	parsed.Get("a")
+	parsed.WithOptions(Options{})
+	if got := parsed.Get("a").Pretty(); strings.Contains(got, "1,000") {
+		t.Fatalf("expected child parsed before WithOptions to use node options, got %q", got)
+	}
+	if got := New(data).WithOptions(Options{}).Get("a").Pretty(); strings.Contains(got, "1,000") {
+		t.Fatalf("expected child from Get to use node options, got %q", got)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefaultOptions(Options{ThousandsSeparator: true})
+		}()
+		go func() {
+			defer wg.Done()
+			New(data).Pretty()
+		}()
+	}
+	wg.Wait()
+	// 生成的补丁不受格式选项影响
+	if ops := Patch(New(`{a: 1}`), New(`{a: 1000}`)); ops[0].Value != "1000" {
+		t.Fatalf("Patch() = %+v", ops)
+	}
+}
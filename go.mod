@@ -1,3 +1,5 @@
 module github.com/jyz1024/pjson5
 
 go 1.23.3
+
+require golang.org/x/text v0.28.0
@@ -0,0 +1,94 @@
+package pjson5
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ValueHook 值钩子：解析对象或数组时，key与Pattern匹配的成员的值原文（不含前后的注释）会先交给Fn处理，
+// 子节点使用Fn返回的JSON5原文，可用于展开 include 指令、解密加密的值等。Fn的参数为成员的点分路径与值的原文，
+// 返回错误时父节点解析失败。
+// Pattern不含 . 时按 path.Match 的规则匹配key，如 secret_*；包含 . 时逐段匹配完整路径，如 servers.*.password。
+// Pattern不合法时父节点解析失败
+type ValueHook struct {
+	Pattern string
+	Fn      func(path, raw string) (string, error)
+}
+
+// match 判断钩子是否匹配path
+func (h ValueHook) match(nodePath []string) (bool, error) {
+	segments := strings.Split(h.Pattern, ".")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false, err
+		}
+	}
+	if len(segments) == 1 {
+		ok, _ := path.Match(segments[0], nodePath[len(nodePath)-1])
+		return ok, nil
+	}
+	if len(segments) != len(nodePath) {
+		return false, nil
+	}
+	for i, segment := range segments {
+		if ok, _ := path.Match(segment, nodePath[i]); !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// memberNode 使用raw[start:end]创建key对应的子节点，并依次执行匹配的值钩子，钩子返回错误时记录在当前节点上
+func (n *Node) memberNode(key string, start, end int) *Node {
+	child := n.subNode(start, end)
+	hooks := n.options().ValueHooks
+	if len(hooks) == 0 {
+		return child
+	}
+	childPath := append(n.nodePath(), key)
+	for _, hook := range hooks {
+		ok, err := hook.match(childPath)
+		if err != nil {
+			n.err = n.newParseError(start, fmt.Sprintf("invalid value hook pattern %q: %v", hook.Pattern, err), err)
+			return child
+		}
+		if !ok {
+			continue
+		}
+		raw, err := hook.Fn(joinPath(childPath), child.raw)
+		if err != nil {
+			n.err = n.newParseError(start, fmt.Sprintf("value hook for %s: %v", joinPath(childPath), err), err)
+			return child
+		}
+		// 钩子返回的原文不在原始文档中，其中的错误位置相对于该原文计算
+		child.raw, child.src, child.offset = raw, "", 0
+	}
+	return child
+}
+
+// nodePath 计算节点相对于根节点的路径：沿父节点向上，在父节点的子节点中查找当前节点对应的key。
+// 节点已从父节点中移除，或是拷贝得到的独立文档时，路径从该节点开始计算
+func (n *Node) nodePath() []string {
+	var nodePath []string
+	for node := n; node.parent != nil; node = node.parent {
+		key, ok := node.parent.childKey(node)
+		if !ok {
+			break
+		}
+		nodePath = append(nodePath, key)
+	}
+	slices.Reverse(nodePath)
+	return nodePath
+}
+
+// childKey 返回子节点child对应的key
+func (n *Node) childKey(child *Node) (string, bool) {
+	for key, node := range n.children {
+		if node == child {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,81 @@
+package pjson5
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOptions_ValueHooks(t *testing.T) {
+	var calls []string
+	opts := ParseOptions{ValueHooks: []ValueHook{
+		{Pattern: "secret_*", Fn: func(path, raw string) (string, error) {
+			calls = append(calls, path+"="+raw)
+			if raw == "'bad'" {
+				return "", errors.New("cannot decrypt")
+			}
+			return strings.ToUpper(raw), nil
+		}},
+		{Pattern: "servers.*.port", Fn: func(path, raw string) (string, error) {
+			calls = append(calls, path+"="+raw)
+			return raw + "0", nil
+		}},
+	}}
+
+	node := NewWithOptions(`{
+  secret_key: 'abc', // 密钥
+  servers: [{port: 8, secret_token: "x"}],
+  port: 9,
+}`, opts)
+	if got := node.Get("secret_key").Value(); got != "'ABC'" {
+		t.Fatalf("secret_key = %q, want 'ABC'", got)
+	}
+	if got := node.Get("servers.0.port").Value(); got != "80" {
+		t.Fatalf("servers.0.port = %q, want 80", got)
+	}
+	if got := node.Get("servers.0.secret_token").Value(); got != `"X"` {
+		t.Fatalf("servers.0.secret_token = %q, want \"X\"", got)
+	}
+	if got := node.Get("port").Value(); got != "9" {
+		t.Fatalf("port = %q, want 9", got)
+	}
+	node.Get("secret_key") // 已解析的节点不会再次执行钩子
+	want := []string{"secret_key='abc'", "servers.0.port=8", `servers.0.secret_token="x"`}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("hook calls = %q, want %q", calls, want)
+	}
+	if !strings.Contains(node.Pretty(), "secret_key: 'ABC', // 密钥") {
+		t.Fatalf("expected comments to be kept:\n%s", node.Pretty())
+	}
+	if New(`{secret_key: 'abc'}`).Get("secret_key").Value() != "'abc'" {
+		t.Fatal("expected hooks to apply only to nodes parsed with the options")
+	}
+
+	// 通过 Set、SetRawValue 写入以及拷贝得到的节点按其在文档中的位置匹配钩子
+	node.Set("servers.1", map[string]int{"port": 9})
+	if got := node.Get("servers.1.port").Value(); got != "90" {
+		t.Fatalf("servers.1.port after Set = %q, want 90", got)
+	}
+	if err := node.Get("servers.0").SetRawValue(`{port: 7}`); err != nil {
+		t.Fatal("SetRawValue() error:", err)
+	}
+	if got := node.Get("servers.0.port").Value(); got != "70" {
+		t.Fatalf("servers.0.port after SetRawValue = %q, want 70", got)
+	}
+	c := NewWithOptions(`{servers: [{port: 1}]}`, opts)
+	c.Get("servers")
+	if got := c.clone().Get("servers.0.port").Value(); got != "10" {
+		t.Fatalf("servers.0.port on a clone = %q, want 10", got)
+	}
+
+	err := NewWithOptions(`{secret_a: 'bad'}`, opts).Parse().Error()
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Offset != 11 || err.Error() != "value hook for secret_a: cannot decrypt" {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	bad := ParseOptions{ValueHooks: []ValueHook{{Pattern: "a.[", Fn: nil}}}
+	if err := NewWithOptions(`{a: {b: 1}}`, bad).Parse().Error(); err == nil || !strings.Contains(err.Error(), `invalid value hook pattern "a.["`) {
+		t.Fatalf("expected invalid pattern error, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package pjson5
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IncludeKey 引入其他文件的指令的key，如 { $include: "common.json5", port: 8080 }
+const IncludeKey = "$include"
+
+// ResolveIncludes 返回展开全部 $include 指令后的新文档，原节点不会被修改。
+// $include 的值为文件路径字符串，相对路径相对于所在文件的目录，文档本身相对于baseDir。
+// 被引入的文件会先递归展开其中的指令，再与指令所在的对象合并：引入的成员插入到 $include 所在的位置，对象中与 $include
+// 同级的key覆盖引入的同名key（两边都是对象时递归合并），覆盖的key没有注释时沿用引入的注释，对象自身的注释保持不变，
+// 被引入文件中对象之外的注释会被丢弃。对象中只有 $include 时可以引入非对象的值，此时整体替换为引入的值。
+// loader用于读取文件，参数为拼接了目录的路径，为nil时使用 os.ReadFile。循环引入、读取或解析失败时返回错误
+func (n *Node) ResolveIncludes(baseDir string, loader func(path string) ([]byte, error)) (*Node, error) {
+	if loader == nil {
+		loader = os.ReadFile
+	}
+	if n.parse().Error() != nil {
+		return nil, n.err
+	}
+	c := n.clone()
+	if err := c.resolveIncludes(baseDir, loader, nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// resolveIncludes 展开当前节点及其子节点中的 $include 指令，chain为正在展开的文件，用于检测循环引入
+func (n *Node) resolveIncludes(baseDir string, loader func(path string) ([]byte, error), chain []string) error {
+	if n.parse().Error() != nil {
+		return n.err
+	}
+	for _, key := range n.orderedKeys() {
+		if n.typ == Object && key == IncludeKey {
+			continue
+		}
+		if err := n.children[key].resolveIncludes(baseDir, loader, chain); err != nil {
+			return err
+		}
+	}
+	directive, ok := n.children[IncludeKey]
+	if n.typ != Object || !ok {
+		return nil
+	}
+	if directive.parse().Error() != nil {
+		return directive.err
+	}
+	if directive.typ != String {
+		return fmt.Errorf("%s value is %s, not String", IncludeKey, directive.typ)
+	}
+	file, err := unquote(directive.val)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(baseDir, file)
+	}
+	if slices.Contains(chain, file) {
+		return fmt.Errorf("include cycle: %s", strings.Join(append(chain, file), " -> "))
+	}
+	data, err := loader(file)
+	if err != nil {
+		return fmt.Errorf("include %s: %w", file, err)
+	}
+	included := &Node{raw: string(data), opts: n.opts}
+	if err = included.resolveIncludes(filepath.Dir(file), loader, append(chain[:len(chain):len(chain)], file)); err != nil {
+		return fmt.Errorf("include %s: %w", file, err)
+	}
+	if included.typ != Object {
+		if len(n.children) > 1 {
+			return fmt.Errorf("include %s: value is %s, not Object", file, included.typ)
+		}
+		n.replaceWith(included)
+		return nil
+	}
+	n.mergeIncluded(included)
+	return nil
+}
+
+// mergeIncluded 将引入的对象base中的成员合并到当前对象：当前对象中不存在的key连同其注释插入到 $include 所在的位置
+// （嵌套的对象中插入到第一个成员之前），两边都是对象的key递归合并，其余同名key保留当前对象的值，没有注释时沿用base中的注释。
+// 当前对象自身的注释与换行保持不变，$include 之前与同一行的注释保留在插入的成员之前
+func (n *Node) mergeIncluded(base *Node) {
+	multiLine := isMultiLine(n)
+	at := -1
+	if start, keyIdx, end := n.keyBlockRange(IncludeKey); keyIdx >= 0 {
+		kept := append([]dataBlock{}, n.block[start:keyIdx]...)
+		for _, block := range n.block[keyIdx+1 : end] {
+			if !block.Is(dataTypeComment | dataTypeCommentLine) {
+				continue
+			}
+			if multiLine { // 同一行的注释改为独占一行
+				block.Typ = dataTypeComment
+				if kept = append(kept, block); !endsWithLineBreak(block) {
+					kept = append(kept, dataBlock{Typ: dataTypeLineBreak})
+				}
+				continue
+			}
+			kept = append(kept, block)
+		}
+		if multiLine && end < len(n.block) && n.block[end].Typ == dataTypeLineBreak {
+			end++
+		}
+		delete(n.children, IncludeKey)
+		n.block = append(n.block[:start], append(kept, n.block[end:]...)...)
+		at = start + len(kept)
+	} else if keys := n.orderedKeys(); len(keys) > 0 {
+		at, _, _ = n.keyBlockRange(keys[0])
+	} else {
+		for at = len(n.block) - 1; at > 0 && n.block[at].Typ != dataTypeEndFlag; at-- {
+		}
+		for at > 0 && n.ownLineComment(at-1) {
+			at--
+		}
+	}
+	var inserted []dataBlock
+	for _, key := range base.orderedKeys() {
+		src := base.children[key]
+		if dst, ok := n.children[key]; ok {
+			if dst.IsObject() && src.IsObject() {
+				dst.mergeIncluded(src)
+			}
+			if leading, inner, trailing := n.keyComments(key); len(leading)+len(inner)+len(trailing) == 0 {
+				leading, inner, trailing = base.keyComments(key)
+				n.setKeyComments(key, leading, inner, trailing)
+			}
+			continue
+		}
+		n.children[key], src.parent = src, n
+		start, _, end := base.keyBlockRange(key)
+		for _, block := range base.block[start:end] {
+			if multiLine || block.Typ != dataTypeLineBreak {
+				inserted = append(inserted, block)
+			}
+		}
+		if multiLine && !endsWithLineBreak(inserted[len(inserted)-1]) {
+			inserted = append(inserted, dataBlock{Typ: dataTypeLineBreak})
+		}
+	}
+	n.block = append(n.block[:at], append(inserted, n.block[at:]...)...)
+	n.fixMemberCommas()
+	if !multiLine && slices.ContainsFunc(inserted, endsWithLineBreak) { // 单行的对象中插入了行注释，按结构重新排版为多行
+		n.reformat()
+	}
+}
+
+// fixMemberCommas 为后面还有成员但缺少逗号的值补上逗号
+func (n *Node) fixMemberCommas() {
+	pending := -1
+	for i := 0; i < len(n.block); i++ {
+		switch n.block[i].Typ {
+		case dataTypeVal:
+			pending = i
+		case dataTypeComma:
+			pending = -1
+		case dataTypeKey:
+			if pending >= 0 {
+				n.block = append(n.block[:pending+1], append([]dataBlock{{Typ: dataTypeComma}}, n.block[pending+1:]...)...)
+				i++
+			}
+			pending = -1
+		}
+	}
+}
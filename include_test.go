@@ -0,0 +1,88 @@
+package pjson5
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestNode_ResolveIncludes(t *testing.T) {
+	files := map[string]string{
+		"conf/common.json5":   "// 公共配置\n{\n  // 日志级别\n  level: 'info',\n  db: {host: 'localhost', port: 5432}, // 数据库\n}\n",
+		"conf/db.json5":       "{ $include: 'sub/pool.json5', host: 'db1' }",
+		"conf/sub/pool.json5": "{size: 10}",
+		"conf/list.json5":     "[1, 2]",
+		"conf/a.json5":        "{ $include: 'b.json5' }",
+		"conf/b.json5":        "{ $include: 'a.json5' }",
+		"conf/bad.json5":      "{a: ",
+	}
+	loader := func(path string) ([]byte, error) {
+		data, ok := files[filepath.ToSlash(path)]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(data), nil
+	}
+	node := New(`// 服务配置
+{ // 本地覆盖
+  // 公共部分
+  $include: "common.json5",
+  // 覆盖日志级别
+  level: 'debug',
+  db: {port: 5433},
+  ports: {$include: 'list.json5'}, // 端口
+  pool: {$include: 'db.json5'},
+}
+// 结束`)
+	got, err := node.ResolveIncludes("conf", loader)
+	if err != nil {
+		t.Fatal("ResolveIncludes() error:", err)
+	}
+	want := "// 服务配置\n{ // 本地覆盖\n  // 公共部分\n// 覆盖日志级别\n  level: 'debug', \n  db: {     host: 'localhost',\n    port: 5433  }, // 数据库\n" +
+		"  ports: [ 1, 2  ], // 端口\n  pool: {     size: 10,\n    host: 'db1'  }, \n}\n// 结束"
+	if got.Pretty() != want {
+		t.Fatalf("ResolveIncludes() = %q, want %q", got.Pretty(), want)
+	}
+	// 引入的成员插入到 $include 所在的位置
+	got, err = New("{\n  name: 'x',\n  $include: 'sub/pool.json5', // 连接池\n  host: 'h'\n}").ResolveIncludes("conf", loader)
+	if err != nil {
+		t.Fatal("ResolveIncludes() error:", err)
+	}
+	want = "{\n  name: 'x', \n  // 连接池\n  size: 10, \n  host: 'h'\n}"
+	if got.Pretty() != want {
+		t.Fatalf("ResolveIncludes() = %q, want %q", got.Pretty(), want)
+	}
+	// 单行的对象中引入带行注释的成员时重新排版为多行
+	got, err = New("{ $include: 'common.json5', level: 'warn' }").ResolveIncludes("conf", loader)
+	if err != nil {
+		t.Fatal("ResolveIncludes() error:", err)
+	}
+	want = "{\n  db: {     host: 'localhost',\n    port: 5432  }, // 数据库\n  // 日志级别\n  level: 'warn'\n}"
+	if got.Pretty() != want {
+		t.Fatalf("ResolveIncludes() = %q, want %q", got.Pretty(), want)
+	}
+	if !node.Exists("$include") {
+		t.Fatal("expected the original node to be unchanged")
+	}
+
+	errTests := []struct {
+		data    string
+		wantErr string
+	}{
+		{data: `{x: {$include: 'a.json5'}}`, wantErr: "include conf/a.json5: include conf/b.json5: include cycle: conf/a.json5 -> conf/b.json5 -> conf/a.json5"},
+		{data: `{$include: 'missing.json5'}`, wantErr: "include conf/missing.json5: file does not exist"},
+		{data: `{$include: 'list.json5', a: 1}`, wantErr: "include conf/list.json5: value is Array, not Object"},
+		{data: `{$include: 1}`, wantErr: "$include value is Number, not String"},
+		{data: `{$include: 'bad.json5'}`, wantErr: "include conf/bad.json5: unexpected end of JSON5 input at position 4"},
+	}
+	for _, tt := range errTests {
+		_, err := New(tt.data).ResolveIncludes("conf", loader)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Fatalf("ResolveIncludes(%s) error = %v, want %q", tt.data, err, tt.wantErr)
+		}
+	}
+	if _, err := New(`{$include: 'missing.json5'}`).ResolveIncludes("conf", loader); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, got %v", err)
+	}
+}
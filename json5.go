@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
@@ -20,6 +24,8 @@ const (
 	lineBreak = "\n"
 	quot      = "\""
 	Root      = "$"
+
+	lineBreakMaxLen = 3 // 换行符的最大字节数（U+2028/U+2029）
 )
 
 var (
@@ -30,6 +36,7 @@ var (
 
 var (
 	errParseJsonErrorTmpl = "invalid JSON5 value at position %d: %s"
+	errUnexpectedEOFTmpl  = "unexpected end of JSON5 input at position %d"
 )
 
 const (
@@ -42,11 +49,14 @@ const (
 	dataTypeComma
 	dataTypeEndFlag
 	dataTypeLineBreak
+	dataTypeShebang // 文档首行的 #! 行，包含结尾的换行
 )
 
 type dataBlock struct {
 	Typ int32  // 数据类型
 	Val string // 数据内容
+	Key string // 解码转义后的key，仅Key类型使用
+	Pos int    // 解析得到的逗号在原始文档中的位置+1，仅Comma类型使用，新增的逗号为0
 }
 
 func (db dataBlock) Is(multiTyp int32) bool {
@@ -57,9 +67,31 @@ func (db dataBlock) KeyUnQuot() string {
 	if db.Typ != dataTypeKey {
 		return db.Val
 	}
-	s := strings.Trim(db.Val, quot)
-	s = strings.Trim(s, "'")
-	return s
+	if db.Key != "" {
+		return db.Key
+	}
+	key, _ := decodeKey(db.Val)
+	return key
+}
+
+// decodeKey 去掉key的引号并解码其中的转义字符，解码失败时返回仅去掉引号的key
+func decodeKey(raw string) (string, error) {
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		key, err := unquote(raw)
+		if err != nil {
+			return raw[1 : len(raw)-1], err
+		}
+		return key, nil
+	}
+	if !strings.Contains(raw, "\\") {
+		return raw, nil
+	}
+	// 非引号key中允许使用 \uXXXX 转义
+	key, err := decodeEscapes(raw)
+	if err != nil {
+		return raw, err
+	}
+	return key, nil
 }
 
 type Type int
@@ -81,6 +113,27 @@ const (
 	Object
 )
 
+// String 返回类型的名称
+func (t Type) String() string {
+	switch t {
+	case None:
+		return "None"
+	case Null:
+		return "Null"
+	case Boolean:
+		return "Boolean"
+	case Number:
+		return "Number"
+	case String:
+		return "String"
+	case Array:
+		return "Array"
+	case Object:
+		return "Object"
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
 type Node struct {
 	raw    string // 原始未解析值，用于懒解析
 	parsed bool   // 是否已经解析过了
@@ -90,8 +143,18 @@ type Node struct {
 	val      string           // 解析后的值部分(对于非数组/对象类型为不含注释的raw，数组对象类型为开始位置到结束位置之间的值)
 	children map[string]*Node // 子节点元素信息,仅Object结构
 
-	parseIdx int   // 当前解析位置
-	err      error // 解析失败信息
+	parseIdx int           // 当前解析位置
+	offset   int           // raw在原始文档中的起始位置，用于错误定位
+	src      string        // raw所在的原始文档，用于计算错误的行列号，为空时即raw本身
+	err      error         // 解析失败信息
+	errs     []ParseError  // CollectErrors模式下收集到的全部解析错误
+	opts     *ParseOptions // 解析选项，nil表示默认选项，子节点与父节点共享
+	format   *Options      // Pretty使用的格式选项，nil表示包级默认选项，子节点从父节点继承
+
+	incomplete bool     // AllowPartial模式下输入在值结束前终止，等待更多输入
+	shadowed   []string // AllowDuplicateKeys模式下被后出现的同名key覆盖的key
+	parent     *Node    // 父节点，用于按需计算值钩子匹配的路径
+	rat        *big.Rat // ExactNumbers模式下数字的精确值
 }
 
 func New(json string) *Node {
@@ -113,11 +176,30 @@ func (n *Node) Error() error {
 	return n.err
 }
 
+// UnmarshalJSON 实现 json.Unmarshaler，仅保存原始数据，访问时再懒解析，用法类似 json.RawMessage
+func (n *Node) UnmarshalJSON(data []byte) error {
+	if n == nil {
+		return errors.New("pjson5: UnmarshalJSON on nil pointer")
+	}
+	*n = Node{raw: string(data), opts: n.opts, parent: n.parent}
+	return nil
+}
+
+// newChild 创建与当前节点共享解析选项与格式选项的子节点
+func (n *Node) newChild(raw string) *Node {
+	return &Node{raw: raw, opts: n.opts, format: n.format, parent: n}
+}
+
+// subNode 使用raw[start:end]创建子节点，并记录其在原始文档中的位置
+func (n *Node) subNode(start, end int) *Node {
+	return &Node{raw: n.raw[start:end], opts: n.opts, format: n.format, parent: n, offset: n.offset + start, src: n.source()}
+}
+
 func (n *Node) exceptLineBreak(pos int) bool {
 	if pos >= len(n.raw) {
 		return false
 	}
-	return n.raw[pos] == lineBreak[0]
+	return lineBreakLen(n.raw, pos) > 0
 }
 
 func (n *Node) except(c byte) bool {
@@ -136,55 +218,87 @@ func (n *Node) parse() *Node {
 		return n
 	}
 	n.parsed = true
+	if n.options().AllowShebang && n.offset == 0 && strings.HasPrefix(n.raw, "#!") {
+		n.parseShebang()
+	}
+	n.parseValue()
+	if n.options().CollectErrors {
+		n.recoverErr()
+		if len(n.errs) > 0 {
+			n.err = &n.errs[0]
+		}
+	}
+	return n
+}
+
+func (n *Node) parseValue() {
 parse:
 	if n.err != nil {
-		return n
+		return
 	}
 	var skipLB, containsLB bool
 	n.parseIdx, skipLB = skipWhiteSpace(n.raw, n.parseIdx) // 跳过所有的空白字符
 	startIdx := n.parseIdx
 	if n.parseIdx >= len(n.raw) {
-		n.parseErr(n.parseIdx)
-		return n
+		if len(n.block) > 0 && n.options().AllowCommentOnly { // 只有注释的文档
+			n.typ = None
+			return
+		}
+		n.eofErr(n.parseIdx)
+		n.stopAtEOF(len(n.block))
+		return
 	}
-	switch n.raw[n.parseIdx] {
-	case backslash:
+	if n.raw[n.parseIdx] == backslash {
 		containsLB, _ = n.parseComment(true, skipLB || containsLB)
 		goto parse
-	case '{':
-		n.typ = Object
+	}
+	concatenated := false
+	n.typ = n.peekType()
+	switch n.typ {
+	case Object:
 		n.children = make(map[string]*Node)
 		n.parseObject()
-	case '[':
-		n.typ = Array
+	case Array:
 		n.children = make(map[string]*Node)
 		n.parseArray()
-	case '"', '\'':
-		n.typ = String
-		n.parseString()
-	case 't', 'f':
-		n.typ = Boolean
+	case String:
+		concatenated = n.parseStringValue()
+	case Boolean:
 		n.parseBoolean()
-	case 'n':
-		n.typ = Null
+	case Null:
 		n.parseNull()
-	case '-', '+', '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'I', 'N':
-		n.typ = Number
+	case Number:
 		n.parseNumber()
 	default:
 		n.parseErr(n.parseIdx)
 	}
-	if n.err != nil {
-		return n
+	if n.err != nil && n.typ != Object && n.typ != Array {
+		if n.stopAtEOF(len(n.block)); n.incomplete { // 未结束的标量视为不存在
+			n.typ = None
+		}
+	}
+	if n.err != nil || n.incomplete {
+		return
 	}
 	if n.typ != Object && n.typ != Array && startIdx < n.parseIdx {
 		n.block = append(n.block, dataBlock{Typ: dataTypeVal})
 		n.val = n.raw[startIdx:n.parseIdx]
 	}
+	if n.typ == Number && n.options().ExactNumbers {
+		n.rat, _ = exactNumber(n.val) // parseNumber已检查过
+	}
+	if concatenated { // 拼接的字符串保存为拼接后的单个字符串
+		joined, err := joinStringLiterals(n.val)
+		if err != nil {
+			n.parseErr(startIdx)
+			return
+		}
+		n.val = joined
+	}
 	// 末尾逗号
 	n.parseIdx = skipLineWhiteSpace(n.raw, n.parseIdx)
 	if n.except(comma) {
-		n.block = append(n.block, dataBlock{Typ: dataTypeComma})
+		n.block = append(n.block, n.commaBlock())
 		n.parseIdx++
 	}
 	// 末尾换行
@@ -202,42 +316,166 @@ parse:
 		}
 		containsLB, _ = n.parseComment(true, skipLB || containsLB)
 	}
-	return n
+}
+
+// peekType 根据当前位置的首字符判断值的类型，无法识别时返回None
+func (n *Node) peekType() Type {
+	switch c := n.raw[n.parseIdx]; c {
+	case '{':
+		return Object
+	case '[':
+		return Array
+	case '"', '\'':
+		return String
+	case 't', 'f':
+		return Boolean
+	case 'n', 'N':
+		// LenientNull 模式下允许 NULL/Null 等写法，其余以N开头的按NaN处理
+		if c == 'n' || n.options().LenientNull && n.parseIdx+4 <= len(n.raw) &&
+			strings.EqualFold(n.raw[n.parseIdx:n.parseIdx+4], "null") {
+			return Null
+		}
+		return Number
+	case '-', '+', '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'I':
+		return Number
+	}
+	return None
 }
 
 func (n *Node) parseErr(parseIdx int) {
-	n.err = fmt.Errorf(errParseJsonErrorTmpl, parseIdx, trimStringPart(n.raw, parseIdx, errTrimStringPartLen))
+	msg := fmt.Sprintf(errParseJsonErrorTmpl, n.offset+parseIdx, trimStringPart(n.raw, parseIdx, errTrimStringPartLen))
+	n.err = n.newParseError(parseIdx, msg, nil)
+}
+
+// eofErr 记录在值结束前到达输入末尾的错误，错误可通过 errors.Is(err, ErrUnexpectedEOF) 识别
+func (n *Node) eofErr(parseIdx int) {
+	n.err = n.newParseError(parseIdx, fmt.Sprintf(errUnexpectedEOFTmpl, n.offset+parseIdx), ErrUnexpectedEOF)
+}
+
+// newParseError 创建位于raw[parseIdx]处的解析错误，并计算其在原始文档中的行列号
+func (n *Node) newParseError(parseIdx int, msg string, err error) *ParseError {
+	offset := n.offset + parseIdx
+	line, column := lineColumn(n.source(), offset)
+	return &ParseError{Offset: offset, Line: line, Column: column, Msg: msg, Err: err}
+}
+
+// parseShebang 将文档首行的 #! 行记录为一个block，只在文档开头调用
+func (n *Node) parseShebang() {
+	end, lbLen := indexLineBreak(n.raw, 0)
+	if end < 0 {
+		end, lbLen = len(n.raw), 0
+	}
+	n.parseIdx = end + lbLen
+	n.block = append(n.block, dataBlock{Typ: dataTypeShebang, Val: n.raw[:n.parseIdx]})
+}
+
+// source 返回节点所在的原始文档
+func (n *Node) source() string {
+	if n.src == "" {
+		return n.raw
+	}
+	return n.src
+}
+
+// stopAtEOF 开启AllowPartial时将到达输入末尾的错误转换为等待更多输入的状态，
+// 并丢弃从memberStart开始的未完成成员的block
+func (n *Node) stopAtEOF(memberStart int) {
+	if !n.options().AllowPartial || !errors.Is(n.err, ErrUnexpectedEOF) {
+		return
+	}
+	n.err = nil
+	n.incomplete = true
+	n.block = n.block[:memberStart]
+}
+
+// recoverErr 将当前的解析错误转存到错误列表中，使解析可以继续进行
+func (n *Node) recoverErr() {
+	if n.err == nil {
+		return
+	}
+	var pe *ParseError
+	if !errors.As(n.err, &pe) {
+		pe = n.newParseError(n.parseIdx, n.err.Error(), n.err)
+	}
+	n.errs = append(n.errs, *pe)
+	n.err = nil
+}
+
+// skipToNextMember 错误恢复：跳过出错的成员，定位到同一层级的下一个逗号或结束符
+func (n *Node) skipToNextMember() {
+	depth := 0
+	for n.parseIdx < len(n.raw) && n.err == nil {
+		switch n.raw[n.parseIdx] {
+		case '"', '\'':
+			n.parseString()
+			continue
+		case backslash:
+			n.parseComment(false, false)
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return
+			}
+			depth--
+		case comma:
+			if depth == 0 {
+				return
+			}
+		}
+		n.parseIdx++
+	}
 }
 
 // parseComment 解析注释，返回解析后的位置
 func (n *Node) parseComment(wBlock bool, isNotInLine bool) (endWithLB bool, suc bool) {
 	pos := n.parseIdx
 	if pos+1 >= len(n.raw) {
-		n.err = fmt.Errorf(errParseJsonErrorTmpl, pos+1, trimStringPart(n.raw, pos, errTrimStringPartLen))
+		n.eofErr(pos + 1)
 		return
 	}
+	// 限制注释长度时只在限制范围内查找注释的结束位置
+	searchEnd, limit := len(n.raw), n.options().MaxCommentLength
+	if limit > 0 && pos+limit+lineBreakMaxLen < searchEnd {
+		searchEnd = pos + limit + lineBreakMaxLen
+	}
 	var endIdx int
 	switch n.raw[pos+1] {
 	case backslash:
-		endIdx = strings.Index(n.raw[pos+2:], lineBreak)
+		endIdx, lbLen := indexLineBreak(n.raw[:searchEnd], pos+2)
 		if endIdx == -1 {
+			endIdx = searchEnd
+		}
+		if limit > 0 && endIdx-pos > limit {
+			n.commentTooLong(pos)
+			return
+		}
+		if lbLen == 0 {
 			n.parseIdx = len(n.raw)
 		} else {
-			n.parseIdx = pos + 2 + endIdx + 1 // 包括换行符
+			n.parseIdx = endIdx + lbLen // 包括换行符
 			endWithLB = true
 		}
 	case '*':
-		endIdx = strings.Index(n.raw[pos+2:], "*/")
+		endIdx = strings.Index(n.raw[pos+2:searchEnd], "*/")
+		if limit > 0 && (endIdx == -1 && searchEnd < len(n.raw) || endIdx+4 > limit) {
+			n.commentTooLong(pos)
+			return
+		}
 		if endIdx == -1 {
-			n.err = fmt.Errorf(errParseJsonErrorTmpl, pos+1, trimStringPart(n.raw, pos, errTrimStringPartLen))
+			n.eofErr(len(n.raw))
 			return
 		}
-		skipWhitePos := skipLineWhiteSpace(n.raw, endIdx)
-		if skipWhitePos < len(n.raw) && n.exceptLineBreak(skipWhitePos) {
-			n.parseIdx = skipWhitePos + 1
+		n.parseIdx = pos + 2 + endIdx + 2
+		// 注释之后直接换行时，换行符作为注释的一部分
+		lbPos := skipLineWhiteSpace(n.raw, n.parseIdx)
+		if strings.HasPrefix(n.raw[lbPos:], "\r\n") {
+			lbPos++
+		}
+		if lbPos < len(n.raw) && n.raw[lbPos] != '\r' && lineBreakLen(n.raw, lbPos) > 0 {
+			n.parseIdx = lbPos + lineBreakLen(n.raw, lbPos)
 			endWithLB = true
-		} else {
-			n.parseIdx = pos + 2 + endIdx + 2
 		}
 	default:
 		n.parseIdx++
@@ -257,6 +495,12 @@ func (n *Node) parseComment(wBlock bool, isNotInLine bool) (endWithLB bool, suc
 	return endWithLB, true
 }
 
+// commentTooLong 记录从raw[pos]开始的注释超过 MaxCommentLength 的错误
+func (n *Node) commentTooLong(pos int) {
+	msg := fmt.Sprintf("comment exceeds maximum length %d at position %d", n.options().MaxCommentLength, n.offset+pos)
+	n.err = n.newParseError(pos, msg, ErrCommentTooLong)
+}
+
 func (n *Node) parseObject() {
 	objStartIdx := n.parseIdx
 	n.parseIdx++
@@ -268,12 +512,28 @@ func (n *Node) parseObject() {
 		n.block = append(n.block, dataBlock{Typ: dataTypeLineBreak})
 	}
 	keyBlock := dataBlock{Typ: dataTypeKey}
+	collectErrors := n.options().CollectErrors
+	memberStart := len(n.block) // 当前成员的第一个block，用于丢弃到达末尾时未完成的成员
 	for n.parseIdx < len(n.raw) && n.err == nil {
+		wsStart := n.parseIdx
 		n.parseIdx, skipLB = skipWhiteSpace(n.raw, n.parseIdx)
+		if n.parseIdx >= len(n.raw) {
+			break
+		}
 		if n.raw[n.parseIdx] != backslash {
 			containsLB = false
 		}
 		switch n.raw[n.parseIdx] {
+		case '}', comma:
+			if keyBlock.Val == "" {
+				break
+			}
+			if !n.missingValue(keyBlock.KeyUnQuot(), memberStart) {
+				return
+			}
+			keyBlock.Val, keyBlock.Key = "", ""
+		}
+		switch n.raw[n.parseIdx] {
 		case '}':
 			n.parseIdx++
 			n.block = append(n.block, dataBlock{Typ: dataTypeEndFlag})
@@ -283,44 +543,72 @@ func (n *Node) parseObject() {
 			containsLB, _ = n.parseComment(true, containsLB || skipLB)
 			continue
 		case colon:
+			n.block = append(n.block, dataBlock{Typ: dataTypeColon, Val: n.colonSpacing(wsStart)})
 			n.parseIdx++
-			n.block = append(n.block, dataBlock{Typ: dataTypeColon})
 			continue
 		case comma:
+			n.block = append(n.block, n.commaBlock())
 			n.parseIdx++
-			n.block = append(n.block, dataBlock{Typ: dataTypeComma})
 			continue
 		}
 		startIdx := n.parseIdx
 		var block dataBlock
 		// 判断当前是否解析了key
 		if keyBlock.Val == "" { // 尝试获取到key
+			memberStart = len(n.block)
 			n.parseObjectKey()
 			block = dataBlock{Typ: dataTypeKey}
 		} else {
 			n.parseObjectVal()
 			block = dataBlock{Typ: dataTypeVal}
+			n.stringTooLong(keyBlock.KeyUnQuot(), startIdx)
+			if n.partialContainer(startIdx, keyBlock.KeyUnQuot(), block) {
+				memberStart = len(n.block)
+			}
+			if n.err != nil && collectErrors && !errors.Is(n.err, ErrUnexpectedEOF) { // 跳过错误的值继续解析
+				n.recoverErr()
+				n.skipToNextMember()
+				keyBlock.Val, keyBlock.Key = "", ""
+				continue
+			}
 		}
 		if n.err != nil {
+			n.stopAtEOF(memberStart)
+			n.partialVal(objStartIdx)
 			return
 		}
 		switch block.Typ {
 		case dataTypeKey:
 			keyBlock.Val = n.raw[startIdx:n.parseIdx]
-			block.Val = keyBlock.Val
-			if _, ok := n.children[block.KeyUnQuot()]; ok {
-				n.err = errors.New("repeat key:" + block.KeyUnQuot())
-				return // 重复的key
+			key, err := decodeKey(keyBlock.Val)
+			if err != nil {
+				n.parseErr(startIdx)
+				if !collectErrors {
+					return
+				}
+				n.recoverErr()
+			}
+			keyBlock.Key, block.Val, block.Key = key, keyBlock.Val, key
+			if _, ok := n.children[block.KeyUnQuot()]; ok && n.options().AllowDuplicateKeys {
+				// 保留最后一次出现的值，记录被覆盖的key
+				n.dropShadowedMember(block.KeyUnQuot())
+				n.shadowed = append(n.shadowed, block.KeyUnQuot())
+			} else if ok {
+				n.err = n.newParseError(startIdx, "repeat key:"+block.KeyUnQuot(), nil)
+				if !collectErrors {
+					return // 重复的key
+				}
+				n.recoverErr()
 			}
 		case dataTypeVal:
-			n.children[keyBlock.KeyUnQuot()] = &Node{raw: n.raw[startIdx:n.parseIdx]}
+			n.children[keyBlock.KeyUnQuot()] = n.memberNode(keyBlock.KeyUnQuot(), startIdx, n.parseIdx)
 			keyBlock.Val = ""
 		}
 		n.block = append(n.block, block)
 		if block.Typ == dataTypeVal { // 是否直接换行
 			n.parseIdx = skipLineWhiteSpace(n.raw, n.parseIdx)
 			if n.except(comma) {
-				n.block = append(n.block, dataBlock{Typ: dataTypeComma})
+				n.block = append(n.block, n.commaBlock())
 				n.parseIdx++
 			}
 			n.parseIdx, skipLB = skipWhiteSpace(n.raw, n.parseIdx)
@@ -329,6 +617,52 @@ func (n *Node) parseObject() {
 			}
 		}
 	}
+	if n.err == nil { // 未找到结束符
+		n.eofErr(n.parseIdx)
+	}
+	if keyBlock.Val != "" { // 只有key没有值的成员
+		n.stopAtEOF(memberStart)
+	} else {
+		n.stopAtEOF(len(n.block))
+	}
+	n.partialVal(objStartIdx)
+}
+
+// missingValue 处理key之后直接出现逗号或结束符（如 { "key": /* todo */ }）的成员：
+// 开启 AllowMissingValue 时视为null值并保留注释，否则记录错误，CollectErrors模式下丢弃该成员继续解析。
+// 返回false表示需要停止解析
+func (n *Node) missingValue(key string, memberStart int) bool {
+	hasColon := false
+	for _, block := range n.block[memberStart:] {
+		hasColon = hasColon || block.Typ == dataTypeColon
+	}
+	if hasColon && n.options().AllowMissingValue {
+		n.children[key] = n.newChild("null")
+		n.block = append(n.block, dataBlock{Typ: dataTypeVal})
+		return true
+	}
+	n.err = n.newParseError(n.parseIdx, fmt.Sprintf("missing value for key %s at position %d", key, n.offset+n.parseIdx), nil)
+	if !n.options().CollectErrors {
+		return false
+	}
+	n.recoverErr()
+	n.block = n.block[:memberStart]
+	return true
+}
+
+// colonSpacing 返回当前位置的冒号及其前后的原始空白，如 "key : value" 中的 " : "。
+// 冒号前换行时忽略前置空白，冒号后直接换行时后置空白视为一个空格
+func (n *Node) colonSpacing(wsStart int) string {
+	before := n.raw[wsStart:n.parseIdx]
+	if strings.ContainsAny(before, "\r\n\u2028\u2029") {
+		before = ""
+	}
+	afterEnd := skipLineWhiteSpace(n.raw, n.parseIdx+1)
+	after := n.raw[n.parseIdx+1 : afterEnd]
+	if afterEnd >= len(n.raw) || lineBreakLen(n.raw, afterEnd) > 0 {
+		after = " "
+	}
+	return before + ":" + after
 }
 
 func (n *Node) parseArray() {
@@ -360,20 +694,41 @@ func (n *Node) parseArray() {
 		case backslash:
 			containsLB, _ = n.parseComment(true, containsLB || skipLB)
 			continue
+		case comma: // 元素与逗号之间有注释，如 [1 /* c */, 2]
+			if lastBlockIs(n, dataTypeVal) {
+				n.block = append(n.block, n.commaBlock())
+				n.parseIdx++
+				continue
+			}
 		}
 		startIdx := n.parseIdx
+		memberStart := len(n.block)
 		n.parseObjectVal()
+		n.stringTooLong(strconv.Itoa(elemIdx), startIdx)
+		if n.partialContainer(startIdx, strconv.Itoa(elemIdx), dataBlock{Typ: dataTypeVal, Val: strconv.Itoa(elemIdx)}) {
+			memberStart = len(n.block)
+		}
+		if n.err != nil && n.options().CollectErrors && !errors.Is(n.err, ErrUnexpectedEOF) { // 跳过错误的元素继续解析
+			n.recoverErr()
+			n.skipToNextMember()
+			if n.except(comma) {
+				n.parseIdx++
+			}
+			continue
+		}
 		if n.err != nil {
+			n.stopAtEOF(memberStart)
+			n.partialVal(arrStartIdx)
 			return
 		}
 		key := strconv.Itoa(elemIdx)
-		n.children[key] = &Node{raw: n.raw[startIdx:n.parseIdx]}
+		n.children[key] = n.memberNode(key, startIdx, n.parseIdx)
 		elemIdx++
 		n.block = append(n.block, dataBlock{Typ: dataTypeVal, Val: key})
 		// eagerly consume trailing comma
 		n.parseIdx = skipLineWhiteSpace(n.raw, n.parseIdx)
 		if n.except(comma) {
-			n.block = append(n.block, dataBlock{Typ: dataTypeComma})
+			n.block = append(n.block, n.commaBlock())
 			n.parseIdx++
 		}
 		// record line break after element/comma
@@ -382,47 +737,63 @@ func (n *Node) parseArray() {
 			n.block = append(n.block, dataBlock{Typ: dataTypeLineBreak})
 		}
 	}
-	n.parseErr(n.parseIdx)
+	if n.err == nil { // 未找到结束符
+		n.eofErr(n.parseIdx)
+	}
+	n.stopAtEOF(len(n.block))
+	n.partialVal(arrStartIdx)
+}
+
+// partialContainer 开启AllowPartial时，未结束的对象/数组值作为未完成的子节点保留，返回是否保留了子节点
+func (n *Node) partialContainer(startIdx int, key string, block dataBlock) bool {
+	if !n.options().AllowPartial || !errors.Is(n.err, ErrUnexpectedEOF) {
+		return false
+	}
+	if c := n.raw[startIdx]; c != objectPair[0] && c != arrayPair[0] {
+		return false
+	}
+	n.children[key] = n.subNode(startIdx, len(n.raw))
+	n.block = append(n.block, block)
+	return true
+}
+
+// partialVal 未完成的对象/数组以已读取的全部内容作为值
+func (n *Node) partialVal(startIdx int) {
+	if n.incomplete {
+		n.val = n.raw[startIdx:]
+	}
 }
 
 func (n *Node) parseObjectKey() {
 	// key中间不允许插入注释
-	var endFn func(ch byte) bool
-	skipCharNum := 0
 	if n.raw[n.parseIdx] == '"' || n.raw[n.parseIdx] == '\'' {
-		quotCh := n.raw[n.parseIdx]
-		endFn = func(ch byte) bool {
-			return ch == quotCh
-		}
-		skipCharNum = 1
-	} else {
-		// 找到空白字符或者:的位置
-		endFn = func(ch byte) bool {
-			return isWhitespaceNLB(ch) || ch == colon
-		}
+		// 带引号的key与字符串规则相同，需要跳过其中转义的引号
+		n.parseString()
+		return
 	}
+	// 找到空白字符或者:的位置
 	for i := n.parseIdx + 1; i < len(n.raw); i++ {
-		if endFn(n.raw[i]) {
-			n.parseIdx = i + skipCharNum
+		if n.raw[i] == colon || whitespaceLen(n.raw, i) > 0 || lineBreakLen(n.raw, i) > 0 {
+			n.parseIdx = i
 			return
 		}
 	}
-	n.parseErr(len(n.raw))
+	n.eofErr(len(n.raw))
 }
 
 func (n *Node) parseObjectVal() {
-	switch n.raw[n.parseIdx] {
-	case '{':
+	switch n.peekType() {
+	case Object:
 		n.parseCombineEnd(objectPair)
-	case '[':
+	case Array:
 		n.parseCombineEnd(arrayPair)
-	case '"', '\'':
-		n.parseString()
-	case 't', 'f':
+	case String:
+		n.parseStringValue()
+	case Boolean:
 		n.parseBoolean()
-	case 'n':
+	case Null:
 		n.parseNull()
-	case '-', '+', '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'I', 'N':
+	case Number:
 		n.parseNumber()
 	default:
 		n.parseErr(n.parseIdx)
@@ -454,15 +825,20 @@ func (n *Node) parseCombineEnd(pair [2]byte) {
 		return
 	}
 	if leftFlagNum > 0 {
-		n.parseErr(n.parseIdx)
+		n.eofErr(n.parseIdx)
 		return
 	}
 }
 
 func (n *Node) parseString() {
 	rawStr := n.raw
+	startIdx := n.parseIdx
 	quotCh := rawStr[n.parseIdx] // opening quote: '"' or '\''
-	for i := n.parseIdx + 1; i < len(rawStr); i++ {
+	end := len(rawStr)           // 超过MaxStringLength时无需继续查找结束的引号
+	if limit := n.options().MaxStringLength; limit > 0 && startIdx+limit+2 < end {
+		end = startIdx + limit + 2
+	}
+	for i := n.parseIdx + 1; i < end; i++ {
 		if rawStr[i] > '\\' {
 			continue
 		}
@@ -472,7 +848,7 @@ func (n *Node) parseString() {
 		}
 		if rawStr[i] == '\\' {
 			i++
-			for ; i < len(rawStr); i++ {
+			for ; i < end; i++ {
 				if rawStr[i] > '\\' {
 					continue
 				}
@@ -494,15 +870,77 @@ func (n *Node) parseString() {
 					return
 				}
 			}
-			if i+1 < len(rawStr) {
-				n.parseIdx = i + 1
-			} else {
-				n.parseIdx = i
-			}
-			return
+			break
 		}
 	}
-	n.parseErr(len(rawStr) - 1)
+	if end < len(rawStr) {
+		msg := fmt.Sprintf("string exceeds maximum length %d at position %d", n.options().MaxStringLength, n.offset+startIdx)
+		n.err = n.newParseError(startIdx, msg, ErrStringTooLong)
+		return
+	}
+	n.eofErr(len(rawStr))
+}
+
+// stringTooLong 在超长字符串的错误信息中补充其所在值的完整路径，member为当前节点中从startIdx开始的值对应的成员。
+// 超长的字符串可能位于该值内部的任意层，按不限制长度的规则重新扫描该值以确定路径
+func (n *Node) stringTooLong(member string, startIdx int) {
+	pe, ok := n.err.(*ParseError)
+	if !ok || !errors.Is(pe, ErrStringTooLong) {
+		return
+	}
+	opts := *n.options()
+	opts.MaxStringLength = 0
+	s := newScanner(&Node{raw: n.raw[startIdx:], offset: n.offset + startIdx, src: n.source(), opts: &opts})
+	s.target = pe.Offset
+	s.value()
+	pe.Msg += fmt.Sprintf(" at path %q", joinPath(append([]string{member}, s.path...)))
+}
+
+func (n *Node) parseStringValue() bool {
+	n.parseString()
+	if n.err != nil || !n.options().AllowStringConcat {
+		return false
+	}
+	concatenated := false
+	for {
+		pos, _ := skipWhiteSpace(n.raw, n.parseIdx)
+		if pos >= len(n.raw) || n.raw[pos] != '+' {
+			return concatenated
+		}
+		pos, _ = skipWhiteSpace(n.raw, pos+1)
+		if pos >= len(n.raw) || (n.raw[pos] != '"' && n.raw[pos] != '\'') {
+			n.parseErr(pos)
+			return concatenated
+		}
+		n.parseIdx = pos
+		if n.parseString(); n.err != nil {
+			return concatenated
+		}
+		concatenated = true
+	}
+}
+
+// joinStringLiterals 将以 + 连接的多个字符串字面量合并为一个双引号字符串
+func joinStringLiterals(s string) (string, error) {
+	parser := &Node{raw: s}
+	buf := &strings.Builder{}
+	for parser.parseIdx < len(s) {
+		parser.parseIdx, _ = skipWhiteSpace(s, parser.parseIdx)
+		if parser.except('+') {
+			parser.parseIdx++
+			continue
+		}
+		start := parser.parseIdx
+		if parser.parseString(); parser.err != nil {
+			return "", parser.err
+		}
+		part, err := unquote(s[start:parser.parseIdx])
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(part)
+	}
+	return quoteString(buf.String()), nil
 }
 
 func (n *Node) parseBoolean() {
@@ -524,7 +962,12 @@ func (n *Node) parseBoolean() {
 }
 
 func (n *Node) parseNull() {
-	if n.parseIdx+4 <= len(n.raw) && strings.EqualFold(n.raw[n.parseIdx:n.parseIdx+4], "null") {
+	if n.parseIdx+4 > len(n.raw) {
+		n.parseErr(n.parseIdx)
+		return
+	}
+	literal := n.raw[n.parseIdx : n.parseIdx+4]
+	if literal == "null" || n.options().LenientNull && strings.EqualFold(literal, "null") {
 		n.parseIdx += 4
 		return
 	}
@@ -533,12 +976,34 @@ func (n *Node) parseNull() {
 
 func (n *Node) parseNumber() {
 	// 通过空白字符或者非有效字符找到结束位置
-	endIdx := n.parseIdx + findEndOfNumber(n.raw[n.parseIdx:])
+	allowUnderscore := n.options().AllowNumberUnderscore
+	endIdx := n.parseIdx + findEndOfNumber(n.raw[n.parseIdx:], allowUnderscore)
 	numStr := n.raw[n.parseIdx:endIdx]
+	if validate := n.options().NumberValidator; validate != nil {
+		if err := validate(numStr); err != nil {
+			n.err = n.newParseError(n.parseIdx, fmt.Sprintf("invalid number %s at position %d", numStr, n.offset+n.parseIdx), err)
+			return
+		}
+		n.parseIdx = endIdx
+		return
+	}
+	if allowUnderscore && strings.Contains(numStr, "_") {
+		if !validDigitSeparators(numStr) {
+			n.parseErr(n.parseIdx)
+			return
+		}
+		numStr = strings.ReplaceAll(numStr, "_", "")
+	}
 	if !isValidNumber(numStr) {
 		n.parseErr(n.parseIdx)
 		return
 	}
+	if n.options().ExactNumbers {
+		if _, err := exactNumber(numStr); err != nil {
+			n.err = n.newParseError(n.parseIdx, fmt.Sprintf("%v at position %d", err, n.offset+n.parseIdx), err)
+			return
+		}
+	}
 	n.parseIdx = endIdx
 }
 
@@ -560,70 +1025,165 @@ func isValidNumber(s string) bool {
 	return false
 }
 
+// Pretty 格式化输出文档，使用 WithOptions 设置的格式选项，未设置时使用 SetDefaultOptions 设置的包级默认选项
 func (n *Node) Pretty() string {
+	return n.Format(n.formatOptions())
+}
+
+// RoundTripStable 检查格式化是否幂等：将 Pretty 的输出重新解析并再次格式化，结果应与第一次的输出相同。
+// 两次格式化前都会完整解析整棵树（在拷贝上进行），使每一层都经过格式化。节点解析失败或重新解析失败时返回false，
+// 格式选项中的 ThousandsSeparator 不参与检查
+func (n *Node) RoundTripStable() bool {
+	if n.parse().Error() != nil {
+		return false
+	}
+	c := n.clone()
+	if !c.parseTree() {
+		return false
+	}
+	opts := n.formatOptions()
+	opts.ThousandsSeparator = false // 仅用于展示，输出无法再被解析
+	first := c.Format(opts)
+	again := &Node{raw: first, opts: n.opts}
+	return again.parseTree() && again.Format(opts) == first
+}
+
+// parseTree 解析整棵树，任一节点解析失败时返回false
+func (n *Node) parseTree() bool {
+	ok := true
+	n.walk(nil, func(_ []string, node *Node) bool {
+		ok = ok && node.parse().Error() == nil
+		return ok
+	})
+	return ok
+}
+
+// Format 按指定的格式选项输出文档
+func (n *Node) Format(opts Options) string {
 	if n.err != nil {
 		return n.err.Error()
 	}
+	if opts.ThousandsSeparator || opts.InlineObjectWidth > 0 { // 需要逐个处理数字或度量子树的宽度，在拷贝上解析整棵树
+		n = n.clone()
+		n.parseTree()
+	}
 	buf := &strings.Builder{}
 	buf.Grow(len(n.raw))
 	// 重新组装Node结构返回
-	buildNodeData(buf, n, 0)
+	buildNodeData(buf, n, 0, &opts)
+	if opts.TrimTrailingSpace {
+		return trimTrailingSpace(buf.String())
+	}
 	return buf.String()
 }
 
-func buildNodeData(buf *strings.Builder, node *Node, level int) {
+func buildNodeData(buf *strings.Builder, node *Node, level int, opts *Options) {
 	if !node.parsed {
+		if pair, ok := emptyCollection(node.raw); ok && opts.EmptyCollections != EmptyDefault {
+			buf.WriteString(opts.EmptyCollections.render(pair))
+			return
+		}
 		buf.WriteString(node.raw)
 		return
 	}
 	preKey := ""
-	for idx, block := range node.block {
+	singleLine := !isMultiLine(node)
+	// 开启NoBracketPadding或重新排版时按输出的实际位置决定缩进，单行的对象/数组在成员之间不换行
+	lineAware := opts.NoBracketPadding || opts.lineAware
+	for idx := 0; idx < len(node.block); idx++ {
+		block := node.block[idx]
 		switch block.Typ {
 		case dataTypeComment:
 			buf.Write(bytes.Repeat(placeholder, level))
 			fallthrough
 		case dataTypeCommentLine:
+			if block.Typ == dataTypeCommentLine && opts.CommentColumn > 0 && alignComment(node, idx) {
+				padComment(buf, opts.CommentColumn)
+			} else if idx > 0 && node.block[idx-1].Is(dataTypeVal|dataTypeEndFlag) {
+				// 值或结束符号之后的同行注释与其间隔一个空格，如 ] // end of list
+				buf.WriteByte(space)
+			}
 			buf.WriteString(block.Val)
+			// 与起始符号同行的块注释之后保留一个空格，如 { /* c */ "a": 1 }
+			if afterStartFlag(node, idx) && !endsWithLineBreak(block) && idx+1 < len(node.block) &&
+				!node.block[idx+1].Is(dataTypeLineBreak|dataTypeEndFlag) {
+				buf.WriteByte(space)
+			}
 		case dataTypeStartFlag:
+			if inline, endIdx, ok := inlineObjectData(node, idx, opts); ok {
+				buf.WriteString(inline)
+				idx = endIdx
+				continue
+			}
+			if endIdx, ok := emptyBlocks(node, idx); ok && opts.EmptyCollections != EmptyDefault {
+				pair := objectPair
+				if node.typ == Array {
+					pair = arrayPair
+				}
+				buf.WriteString(opts.EmptyCollections.render(pair))
+				idx = endIdx
+				continue
+			}
 			switch node.typ {
 			case Object:
 				buf.WriteByte(objectPair[0])
 			case Array:
 				buf.WriteByte(arrayPair[0])
 			}
-			if !nextBlockIs(node, idx, dataTypeLineBreak) {
+			if !nextBlockIs(node, idx, dataTypeLineBreak) && !(singleLine && opts.NoBracketPadding) {
 				buf.WriteByte(space)
 			}
 			level++
 		case dataTypeKey:
-			buf.Write(bytes.Repeat(placeholder, level))
+			if !lineAware || atLineStart(buf) {
+				buf.Write(bytes.Repeat(placeholder, level))
+			}
 			buf.WriteString(block.Val)
-			preKey = strings.Trim(block.Val, quot)
+			preKey = block.KeyUnQuot()
 		case dataTypeColon:
+			if opts.PreserveColonSpacing && block.Val != "" {
+				buf.WriteString(block.Val)
+				continue
+			}
 			buf.WriteByte(colon)
 			buf.WriteByte(space)
 		case dataTypeVal:
-			switch node.typ {
-			case Object:
-				buildNodeData(buf, node.children[preKey], level)
-			case Array:
+			switch {
+			case node.typ == Array && !lineAware:
 				if arrayIsMultiLine(node) {
 					buf.Write(bytes.Repeat(placeholder, level))
 				}
-				buildNodeData(buf, node.children[block.Val], level)
+			case node.typ == Object || node.typ == Array:
+				if atLineStart(buf) { // 多行数组的元素，或key之后的注释以换行结束时，值位于行首
+					buf.Write(bytes.Repeat(placeholder, level))
+				}
+			}
+			switch node.typ {
+			case Object:
+				buildNodeData(buf, node.children[preKey], level, opts)
+			case Array:
+				buildNodeData(buf, node.children[block.Val], level, opts)
 			default:
+				if opts.ThousandsSeparator && node.typ == Number {
+					buf.WriteString(groupThousands(node.val))
+					continue
+				}
 				buf.WriteString(node.val)
 			}
 		case dataTypeComma:
 			buf.WriteByte(comma)
-			if nextBlockIs(node, idx, dataTypeKey) || (arrayIsMultiLine(node) && nextBlockIs(node, idx, dataTypeVal)) {
+			if !(lineAware && singleLine) && nextBlockIs(node, idx, dataTypeKey) || (arrayIsMultiLine(node) && nextBlockIs(node, idx, dataTypeVal)) {
 				buf.WriteString(lineBreak)
 			} else {
 				buf.WriteByte(space)
 			}
 		case dataTypeEndFlag:
 			level--
-			buf.Write(bytes.Repeat(placeholder, level))
+			if !lineAware || atLineStart(buf) {
+				buf.Write(bytes.Repeat(placeholder, level))
+			} else if singleLine && !opts.NoBracketPadding && !strings.HasSuffix(buf.String(), string(space)) {
+				buf.WriteByte(space)
+			}
 			switch node.typ {
 			case Object:
 				buf.WriteByte(objectPair[1])
@@ -632,14 +1192,84 @@ func buildNodeData(buf *strings.Builder, node *Node, level int) {
 			}
 		case dataTypeLineBreak:
 			buf.WriteString(lineBreak)
+		case dataTypeShebang:
+			buf.WriteString(block.Val)
 		}
 	}
 }
 
 // arrayIsMultiLine reports whether an Array node uses multi-line formatting
-// (i.e. the first block after the opening '[' is a line break).
+// (i.e. the first block after the opening '[' is a line break, or a comment ending with one).
 func arrayIsMultiLine(node *Node) bool {
-	return node.typ == Array && len(node.block) >= 2 && node.block[1].Typ == dataTypeLineBreak
+	if node.typ != Array || len(node.block) < 2 {
+		return false
+	}
+	first := node.block[1]
+	return first.Typ == dataTypeLineBreak ||
+		node.block[0].Typ == dataTypeStartFlag && first.Is(dataTypeComment|dataTypeCommentLine) && endsWithLineBreak(first)
+}
+
+// isMultiLine 判断Object/Array的开始符与结束符之间是否包含换行
+func isMultiLine(node *Node) bool {
+	started := false
+	for _, block := range node.block {
+		switch {
+		case block.Typ == dataTypeStartFlag:
+			started = true
+		case block.Typ == dataTypeEndFlag:
+			return false
+		case started && endsWithLineBreak(block):
+			return true
+		}
+	}
+	return false
+}
+
+// atLineStart 判断输出是否位于行首
+func atLineStart(buf *strings.Builder) bool {
+	return buf.Len() == 0 || hasLineBreakSuffix(buf.String())
+}
+
+// alignComment 判断idx处的同行注释是否需要按 CommentColumn 对齐：位于值、逗号或括号之后并且到行尾结束
+func alignComment(node *Node, idx int) bool {
+	if idx == 0 || !node.block[idx-1].Is(dataTypeVal|dataTypeComma|dataTypeStartFlag|dataTypeEndFlag) {
+		return false
+	}
+	return endsWithLineBreak(node.block[idx]) || idx+1 == len(node.block) || nextBlockIs(node, idx, dataTypeLineBreak)
+}
+
+// padComment 补齐空格使接下来的注释从第column+1个字符开始，当前行已超过column时保证间隔一个空格
+func padComment(buf *strings.Builder, column int) {
+	s := buf.String()
+	line := s[strings.LastIndexByte(s, '\n')+1:]
+	if pad := column - utf8.RuneCountInString(line); pad > 0 {
+		buf.WriteString(strings.Repeat(string(space), pad))
+	} else if !strings.HasSuffix(line, string(space)) {
+		buf.WriteByte(space)
+	}
+}
+
+// afterStartFlag 判断idx处的block之前是否只有起始符号与同行的注释，即位于起始符号所在行的开头部分
+func afterStartFlag(node *Node, idx int) bool {
+	for i := idx - 1; i >= 0; i-- {
+		switch block := node.block[i]; {
+		case block.Typ == dataTypeStartFlag:
+			return true
+		case block.Typ != dataTypeCommentLine || endsWithLineBreak(block):
+			return false
+		}
+	}
+	return false
+}
+
+// lastBlockIs 判断除注释与换行外的最后一个block是否为typ类型
+func lastBlockIs(node *Node, typ int32) bool {
+	for i := len(node.block) - 1; i >= 0; i-- {
+		if !node.block[i].Is(dataTypeComment | dataTypeCommentLine | dataTypeLineBreak) {
+			return node.block[i].Typ == typ
+		}
+	}
+	return false
 }
 
 func nextBlockIs(node *Node, idx int, typ int32) bool {
@@ -654,6 +1284,78 @@ func (n *Node) Exists(path string) bool {
 	return node.typ != None
 }
 
+// Require 检查paths是否全部存在，值为null的路径视为存在。存在缺失时返回列出全部缺失路径的 *MissingKeysError，
+// 文档解析失败时返回解析错误
+func (n *Node) Require(paths ...string) error {
+	return n.require(paths, false)
+}
+
+// RequireNonNull 与 Require 相同，但值为null的路径也视为缺失
+func (n *Node) RequireNonNull(paths ...string) error {
+	return n.require(paths, true)
+}
+
+func (n *Node) require(paths []string, nonNull bool) error {
+	var missing []string
+	for _, path := range paths {
+		node, ok := n.Lookup(path)
+		if n.err != nil {
+			return n.err
+		}
+		if !ok || nonNull && node.typ == Null {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Paths: missing}
+	}
+	return nil
+}
+
+// RejectUnknownKeys 检查根对象的key是否都在allowed中，存在其他key时返回 UnknownKeysError，按文档顺序列出全部不允许的key。
+// 根节点不是对象时不做检查，解析失败时返回解析错误
+func (n *Node) RejectUnknownKeys(allowed ...string) error {
+	return n.rejectUnknownKeys(allowed, false)
+}
+
+// RejectUnknownKeysRecursive 与 RejectUnknownKeys 相同，但检查任意层级的对象（包括数组中的对象）中的key，
+// allowed为允许的key名称，错误中列出的是不允许的key的完整路径
+func (n *Node) RejectUnknownKeysRecursive(allowed ...string) error {
+	return n.rejectUnknownKeys(allowed, true)
+}
+
+func (n *Node) rejectUnknownKeys(allowed []string, recursive bool) error {
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, key := range allowed {
+		allowedSet[key] = true
+	}
+	var unknown []string
+	var err error
+	n.walk(nil, func(path []string, node *Node) bool {
+		if err != nil {
+			return false
+		}
+		if err = node.parse().Error(); err != nil {
+			return false
+		}
+		if node.typ == Object {
+			for _, key := range node.orderedKeys() {
+				if !allowedSet[key] {
+					unknown = append(unknown, joinPath(append(path[:len(path):len(path)], key)))
+				}
+			}
+		}
+		return recursive
+	})
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return &UnknownKeysError{Paths: unknown}
+	}
+	return nil
+}
+
 func (n *Node) IsExist() bool {
 	return n.Type() != None
 }
@@ -666,32 +1368,97 @@ func (n *Node) IsObject() bool {
 	return n.parse().typ == Object
 }
 
+// IsScalar 判断节点是否为标量：Null、Boolean、Number或String
+func (n *Node) IsScalar() bool {
+	switch n.parse().typ {
+	case Null, Boolean, Number, String:
+		return true
+	}
+	return false
+}
+
+// IsContainer 判断节点是否为对象或数组
+func (n *Node) IsContainer() bool {
+	typ := n.parse().typ
+	return typ == Object || typ == Array
+}
+
 func (n *Node) Get(path string) *Node {
+	node, _ := n.Lookup(path)
+	return node
+}
+
+// Lookup 获取path对应的节点并返回是否存在，不存在或解析失败时返回空节点和false。
+// 数组的元素以下标作为路径片段，负数下标从末尾开始计算，如 list.-1 为最后一个元素，越界时视为不存在
+func (n *Node) Lookup(path string) (*Node, bool) {
 	pPath := parsePath(path)
 	if pPath.onlyRoot() {
-		return n
+		return n, true
 	}
 	pathNode := n
 	for _, nodePath := range pPath.PathNoe {
 		if n.err = pathNode.parse().Error(); n.err != nil {
-			return &Node{}
+			return &Node{}, false
+		}
+		if pathNode.typ == Array {
+			nodePath = pathNode.arrayIndex(nodePath)
 		}
 		node, ok := pathNode.children[nodePath]
 		if !ok { // 没找到节点，直接返回
-			return &Node{}
+			return &Node{}, false
 		}
 		pathNode = node
 	}
 	if n.err = pathNode.parse().Error(); n.err != nil {
-		return &Node{}
+		return &Node{}, false
+	}
+	return pathNode, true
+}
+
+// arrayIndex 将数组路径中的负数下标转换为从末尾开始计算的下标，如 -1 为最后一个元素，其余路径片段原样返回
+func (n *Node) arrayIndex(seg string) string {
+	if !strings.HasPrefix(seg, "-") {
+		return seg
+	}
+	i, err := strconv.Atoi(seg)
+	if err != nil || -i > len(n.children) {
+		return seg
+	}
+	return strconv.Itoa(len(n.children) + i)
+}
+
+// Depth 返回path对应节点的嵌套深度，根节点为0，节点不存在时返回-1
+func (n *Node) Depth(path string) int {
+	if _, ok := n.Lookup(path); !ok {
+		return -1
+	}
+	return len(parsePath(path).PathNoe)
+}
+
+// SourceLine 返回path对应节点在原始文档中所在的整行内容（不含换行符），用于在错误信息中展示上下文。
+// 节点不存在时返回空字符串，通过Set等方式新写入的节点返回其自身文本的首行
+func (n *Node) SourceLine(path string) string {
+	node, ok := n.Lookup(path)
+	if !ok {
+		return ""
 	}
-	return pathNode
+	return lineAt(node.source(), node.offset)
 }
 
 func (n *Node) Delete(path string) *Node {
+	return n.delete(path, false)
+}
+
+// DeleteKeepComments 删除指定路径的节点，但保留被删除key之前独占一行的注释，
+// 这些注释会留在原位置，成为下一个key的前置注释或独立注释
+func (n *Node) DeleteKeepComments(path string) *Node {
+	return n.delete(path, true)
+}
+
+func (n *Node) delete(path string, keepComments bool) *Node {
 	pPath := parsePath(path)
 	if pPath.onlyRoot() {
-		*n = Node{raw: "", parsed: false}
+		*n = Node{raw: "", parsed: false, opts: n.opts, format: n.format, parent: n.parent}
 		return n
 	}
 
@@ -701,6 +1468,9 @@ func (n *Node) Delete(path string) *Node {
 		if n.err = pathNode.parse().Error(); n.err != nil {
 			return n
 		}
+		if pathNode.typ == Array {
+			nodePath = pathNode.arrayIndex(nodePath)
+		}
 		node, ok := pathNode.children[nodePath]
 		if !ok { // 没找到节点，直接返回
 			return n
@@ -712,14 +1482,14 @@ func (n *Node) Delete(path string) *Node {
 		if pathNode.typ == Array {
 			pathNode.deleteArrayNode(nodePath)
 		} else {
-			pathNode.deleteObjectNode(nodePath)
+			pathNode.deleteObjectNode(nodePath, keepComments)
 		}
 	}
 	return n
 }
 
 func (n *Node) insertObjectNode(nodePath string, node *Node) *Node {
-	n.children[nodePath] = node
+	n.children[nodePath], node.parent = node, n
 	endFlagIdx := len(n.block) - 1
 	for endFlagIdx >= 0 {
 		if n.block[endFlagIdx].Typ == dataTypeEndFlag {
@@ -731,15 +1501,20 @@ func (n *Node) insertObjectNode(nodePath string, node *Node) *Node {
 		n.err = errors.New("inner error: end flag not found")
 		return n
 	}
+	// 结束符之前独占一行的注释保持在对象的末尾，新增的key插入到这些注释之前
+	insertIdx := endFlagIdx
+	for insertIdx > 0 && n.ownLineComment(insertIdx-1) {
+		insertIdx--
+	}
 	// 插入新增的block
 	insertBlocks := []dataBlock{
-		{Typ: dataTypeKey, Val: "\"" + nodePath + "\""},
+		{Typ: dataTypeKey, Val: quoteString(nodePath), Key: nodePath},
 		{Typ: dataTypeColon},
 		{Typ: dataTypeVal},
 		{Typ: dataTypeLineBreak},
 	}
-	n.block = append(n.block[:endFlagIdx], append(insertBlocks, n.block[endFlagIdx:]...)...)
-	for i := endFlagIdx - 1; i >= 0; i-- { // 上一个val元素最后添加逗号
+	n.block = append(n.block[:insertIdx], append(insertBlocks, n.block[insertIdx:]...)...)
+	for i := insertIdx - 1; i >= 0; i-- { // 上一个val元素最后添加逗号
 		if n.block[i].Typ == dataTypeComma {
 			break
 		}
@@ -751,7 +1526,7 @@ func (n *Node) insertObjectNode(nodePath string, node *Node) *Node {
 	return n
 }
 
-func (n *Node) deleteObjectNode(nodePath string) *Node {
+func (n *Node) deleteObjectNode(nodePath string, keepComments bool) *Node {
 	// 寻找删除的节点
 	_, ok := n.children[nodePath]
 	if !ok {
@@ -759,34 +1534,77 @@ func (n *Node) deleteObjectNode(nodePath string) *Node {
 	}
 	delete(n.children, nodePath)
 	// 删除关联的block信息
-	keyIdx := 0
+	startIdx, keyIdx, endIdx := n.keyBlockRange(nodePath)
+	// 没找到key所在的block，直接返回
+	if keyIdx < 0 {
+		return n
+	}
+	if keepComments {
+		startIdx = keyIdx
+	}
+	// 删除节点
+	n.block = append(n.block[:startIdx], n.block[endIdx:]...)
+	return n
+}
+
+// dropShadowedMember 删除被覆盖的同名key及其值，成员的注释保留在原位置。成员独占一行时删除该行，
+// 与成员同一行的注释改为独占一行，使注释仍位于后续成员之前
+func (n *Node) dropShadowedMember(key string) {
+	_, keyIdx, end := n.keyBlockRange(key)
+	if keyIdx < 0 {
+		return
+	}
+	lineStart := keyIdx > 0 && endsWithLineBreak(n.block[keyIdx-1])
+	var comments []dataBlock
+	for _, block := range n.block[keyIdx+1 : end] {
+		if !block.Is(dataTypeComment | dataTypeCommentLine) {
+			continue
+		}
+		if !lineStart {
+			comments = append(comments, block)
+			continue
+		}
+		block.Typ = dataTypeComment
+		comments = append(comments, block)
+		if !endsWithLineBreak(block) {
+			comments = append(comments, dataBlock{Typ: dataTypeLineBreak})
+		}
+	}
+	if lineStart && end < len(n.block) && n.block[end].Typ == dataTypeLineBreak {
+		end++
+	}
+	delete(n.children, key)
+	n.block = append(n.block[:keyIdx], append(comments, n.block[end:]...)...)
+}
+
+// keyBlockRange 返回key关联的block区间[start, end)，未找到key时keyIdx为-1。
+// 其中[start, keyIdx)为key之前独占一行的注释，(keyIdx, end)为冒号、值、逗号以及同一行的后置注释
+func (n *Node) keyBlockRange(key string) (start, keyIdx, end int) {
+	keyIdx = 0
 	for ; keyIdx < len(n.block); keyIdx++ {
-		if n.block[keyIdx].Typ == dataTypeKey && n.block[keyIdx].KeyUnQuot() == nodePath {
+		if n.block[keyIdx].Typ == dataTypeKey && n.block[keyIdx].KeyUnQuot() == key {
 			break
 		}
 	}
-	// 没找到key所在的block，直接返回
 	if keyIdx >= len(n.block) {
-		return n
+		return -1, -1, -1
 	}
-	// 寻找开始删除的位置和结束删除的位置
-	startIdx := keyIdx - 1 // 开始位置定位到最后一个Val/comma/CommentLine/StartFlag/LineBreak
-	for ; startIdx > 0; startIdx-- {
-		if n.block[startIdx].Is(dataTypeVal | dataTypeComma | dataTypeCommentLine | dataTypeStartFlag | dataTypeLineBreak) {
+	// 开始位置定位到最后一个Val/comma/CommentLine/StartFlag/LineBreak之后
+	start = keyIdx - 1
+	for ; start > 0; start-- {
+		if n.block[start].Is(dataTypeVal | dataTypeComma | dataTypeCommentLine | dataTypeStartFlag | dataTypeLineBreak) {
 			break
 		}
 	}
-	startIdx += 1
-	// 寻找结束位置, 结束位置定位到下一个Key/EndFlag/Comment/LineBreak
-	endIdx := keyIdx + 1
-	for ; endIdx < len(n.block); endIdx++ {
-		if n.block[endIdx].Is(dataTypeKey | dataTypeEndFlag | dataTypeComment | dataTypeLineBreak) {
+	start += 1
+	// 结束位置定位到下一个Key/EndFlag/Comment/LineBreak
+	end = keyIdx + 1
+	for ; end < len(n.block); end++ {
+		if n.block[end].Is(dataTypeKey | dataTypeEndFlag | dataTypeComment | dataTypeLineBreak) {
 			break
 		}
 	}
-	// 删除节点
-	n.block = append(n.block[:startIdx], n.block[endIdx:]...)
-	return n
+	return start, keyIdx, end
 }
 
 func (n *Node) Set(path string, val any) *Node {
@@ -799,10 +1617,78 @@ func (n *Node) Set(path string, val any) *Node {
 	return n.SetString(path, string(data))
 }
 
+// KV 有序对象中的一个成员，用于 SetOrdered
+type KV struct {
+	Key   string
+	Value any // 按 json.Marshal 序列化，值为 []KV 时作为嵌套的有序对象
+}
+
+// SetOrdered 将path设置为按pairs顺序排列成员的对象，用于保留对阅读者有意义的key顺序，
+// 避免 Set 传入map时被 json.Marshal 按key排序。pairs中重复的key返回错误
+func (n *Node) SetOrdered(path string, pairs []KV) *Node {
+	data, err := marshalOrdered(pairs)
+	if err != nil {
+		n.err = err
+		return n
+	}
+	return n.SetString(path, data)
+}
+
+// marshalOrdered 将pairs按顺序序列化为JSON对象
+func marshalOrdered(pairs []KV) (string, error) {
+	buf := &strings.Builder{}
+	seen := make(map[string]bool, len(pairs))
+	buf.WriteByte(objectPair[0])
+	for i, pair := range pairs {
+		if seen[pair.Key] {
+			return "", fmt.Errorf("duplicate key in ordered object: %s", pair.Key)
+		}
+		seen[pair.Key] = true
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(quoteString(pair.Key))
+		buf.WriteString(": ")
+		if nested, ok := pair.Value.([]KV); ok {
+			data, err := marshalOrdered(nested)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(data)
+			continue
+		}
+		data, err := json.Marshal(pair.Value)
+		if err != nil {
+			return "", fmt.Errorf("marshal data error:%w", err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(objectPair[1])
+	return buf.String(), nil
+}
+
+// SetFloat 按 strconv.FormatFloat(v, format, prec, 64) 的格式写入数字，用于精确控制输出形式，如固定两位小数。
+// format只支持 'e'、'E'、'f'、'g'、'G'，NaN与正负无穷写为 NaN、Infinity、-Infinity
+func (n *Node) SetFloat(path string, v float64, format byte, prec int) *Node {
+	if !strings.ContainsRune("eEfgG", rune(format)) {
+		n.err = fmt.Errorf("unsupported float format: %q", format)
+		return n
+	}
+	switch {
+	case math.IsNaN(v):
+		return n.SetString(path, "NaN")
+	case math.IsInf(v, 1):
+		return n.SetString(path, "Infinity")
+	case math.IsInf(v, -1):
+		return n.SetString(path, "-Infinity")
+	}
+	return n.SetString(path, strconv.FormatFloat(v, format, prec, 64))
+}
+
 func (n *Node) SetString(path string, val string) *Node {
 	pPath := parsePath(path)
 	if pPath.onlyRoot() {
-		*n = Node{raw: val, parsed: false}
+		*n = Node{raw: val, parsed: false, opts: n.opts, format: n.format, parent: n.parent}
 		return n
 	}
 	// 寻找插入位置，如果中间位置不存在，直接创建
@@ -812,22 +1698,38 @@ func (n *Node) SetString(path string, val string) *Node {
 			n.err = pathNode.err
 			return n
 		}
+		if pathNode == n && n.typ == None { // 只有注释的文档，在注释之后创建对象
+			n.typ, n.children = Object, make(map[string]*Node)
+			if !endsWithLineBreak(n.block[len(n.block)-1]) {
+				n.block = append(n.block, dataBlock{Typ: dataTypeLineBreak})
+			}
+			n.block = append(n.block, dataBlock{Typ: dataTypeStartFlag}, dataBlock{Typ: dataTypeLineBreak}, dataBlock{Typ: dataTypeEndFlag})
+		}
 		if pathNode.typ != Object && pathNode.typ != Array {
 			n.err = errors.New("path not found")
 			return n
 		}
+		if pathNode.typ == Array {
+			nodePath = pathNode.arrayIndex(nodePath)
+		}
 		node, ok := pathNode.children[nodePath]
 		if !ok {
 			if pathNode.typ == Array {
 				targetIdx, atoiErr := strconv.Atoi(nodePath)
 				if atoiErr != nil || targetIdx != len(pathNode.children) {
-					n.err = fmt.Errorf("array index out of range: %s", nodePath)
+					n.err = fmt.Errorf("array index out of range: %s (length %d)", nodePath, len(pathNode.children))
 					return n
 				}
-				node = &Node{raw: "", parsed: false}
+				if i == len(pPath.PathNoe)-1 {
+					node = pathNode.newChild("")
+				} else { // 中间路径与对象中的处理一致，创建为对象
+					node = buildObjectNode()
+					node.opts, node.format, node.parent = pathNode.opts, pathNode.format, pathNode
+				}
 				pathNode.insertArrayNode(node)
 			} else {
 				node = buildObjectNode()
+				node.opts, node.format, node.parent = pathNode.opts, pathNode.format, pathNode
 				pathNode.children[nodePath] = node
 				pathNode.insertObjectNode(nodePath, node)
 			}
@@ -843,6 +1745,92 @@ func (n *Node) SetString(path string, val string) *Node {
 	return n
 }
 
+// SetRawValue 使用JSON5原文raw替换当前节点的值，通常用于 Get 得到的节点：节点前后的注释与空白属于父节点，
+// 不受影响。raw只做第一层的语法检查，无效时返回错误并保持原值不变。对根节点调用时原文中的注释也会被替换。
+// 节点为 None 类型（如 Get 不存在的路径得到的节点）时设置并返回 ErrPathNotFound
+func (n *Node) SetRawValue(raw string) error {
+	if n.Type() == None {
+		n.err = ErrPathNotFound
+		return n.err
+	}
+	old := *n
+	*n = Node{raw: raw, opts: n.opts, format: n.format, parent: n.parent}
+	if n.parse().Error() != nil {
+		err := n.err
+		*n = old
+		return err
+	}
+	return nil
+}
+
+// GetOrCreateArray 返回path对应的数组，path不存在时创建一个空数组。path已存在但不是数组时返回错误，不会覆盖原值
+func (n *Node) GetOrCreateArray(path string) *Node {
+	node := n.Get(path)
+	if n.err != nil {
+		return &Node{err: n.err}
+	}
+	if node.IsExist() {
+		if !node.IsArray() {
+			n.err = fmt.Errorf("path is not an array: %s", path)
+			return &Node{err: n.err}
+		}
+		return node
+	}
+	if n.SetString(path, "[]").err != nil {
+		return &Node{err: n.err}
+	}
+	node = n.Get(path)
+	arr := buildArrayNode()
+	arr.opts, arr.format, arr.parent = node.opts, node.format, node.parent
+	*node = *arr
+	return node
+}
+
+// Append 将val追加到path对应数组的末尾，数组不存在时创建
+func (n *Node) Append(path string, val any) *Node {
+	return n.Extend(path, val)
+}
+
+// Extend 将vals依次追加到path对应数组的末尾，数组不存在时创建。任一值序列化失败时不追加任何值
+func (n *Node) Extend(path string, vals ...any) *Node {
+	items := make([]string, 0, len(vals))
+	for _, val := range vals {
+		data, err := json.Marshal(val)
+		if err != nil {
+			n.err = fmt.Errorf("marshal data error:%w", err)
+			return n
+		}
+		items = append(items, string(data))
+	}
+	arr := n.GetOrCreateArray(path)
+	if n.err != nil {
+		return n
+	}
+	for _, item := range items {
+		if arr.insertArrayNode(arr.newChild(item)); arr.err != nil {
+			n.err = arr.err
+			return n
+		}
+	}
+	return n
+}
+
+// CanSet 检查 Set(path, val) 能否成功，返回Set会产生的错误。检查在节点的拷贝上进行，不会修改当前节点
+func (n *Node) CanSet(path string, val any) error {
+	if n.parse().Error() != nil {
+		return n.err
+	}
+	return n.clone().Set(path, val).Error()
+}
+
+// Touch 确保路径存在：路径不存在时以null值创建，已存在时保持原值不变
+func (n *Node) Touch(path string) *Node {
+	if n.Exists(path) || n.err != nil {
+		return n
+	}
+	return n.SetString(path, "null")
+}
+
 func (n *Node) Len() int {
 	if n.parse().typ != Array {
 		return 0
@@ -850,9 +1838,26 @@ func (n *Node) Len() int {
 	return len(n.children)
 }
 
+// ElementType 返回数组元素的公共类型及元素类型是否一致，空数组返回 (None, true)，
+// 元素类型不一致或节点不是数组时返回 (None, false)
+func (n *Node) ElementType() (Type, bool) {
+	if n.parse().typ != Array {
+		return None, false
+	}
+	typ := None
+	for _, child := range n.children {
+		childTyp := child.Type()
+		if childTyp == None || typ != None && childTyp != typ {
+			return None, false
+		}
+		typ = childTyp
+	}
+	return typ, true
+}
+
 func (n *Node) insertArrayNode(node *Node) *Node {
 	idx := strconv.Itoa(len(n.children))
-	n.children[idx] = node
+	n.children[idx], node.parent = node, n
 	endFlagIdx := len(n.block) - 1
 	for endFlagIdx >= 0 {
 		if n.block[endFlagIdx].Typ == dataTypeEndFlag {
@@ -864,12 +1869,17 @@ func (n *Node) insertArrayNode(node *Node) *Node {
 		n.err = errors.New("inner error: end flag not found")
 		return n
 	}
+	// 与对象一致，新增的元素插入到结束符之前独占一行的注释之前
+	insertIdx := endFlagIdx
+	for insertIdx > 0 && n.ownLineComment(insertIdx-1) {
+		insertIdx--
+	}
 	insertBlocks := []dataBlock{
 		{Typ: dataTypeVal, Val: idx},
 		{Typ: dataTypeLineBreak},
 	}
-	n.block = append(n.block[:endFlagIdx], append(insertBlocks, n.block[endFlagIdx:]...)...)
-	for i := endFlagIdx - 1; i >= 0; i-- {
+	n.block = append(n.block[:insertIdx], append(insertBlocks, n.block[insertIdx:]...)...)
+	for i := insertIdx - 1; i >= 0; i-- {
 		if n.block[i].Typ == dataTypeComma {
 			break
 		}
@@ -881,6 +1891,11 @@ func (n *Node) insertArrayNode(node *Node) *Node {
 	return n
 }
 
+// isInlineComment 判断block是否为不以换行结束的同行注释，如 /* x */
+func isInlineComment(block dataBlock) bool {
+	return block.Typ == dataTypeCommentLine && !endsWithLineBreak(block)
+}
+
 func (n *Node) deleteArrayNode(idxStr string) *Node {
 	_, ok := n.children[idxStr]
 	if !ok {
@@ -898,21 +1913,56 @@ func (n *Node) deleteArrayNode(idxStr string) *Node {
 	if valIdx < 0 {
 		return n
 	}
-	// include the preceding LB in the deletion range (preserves multi-line formatting)
+	// an inline comment between the preceding comma and the element belongs to the element, e.g. [1, /*x*/ 3]
 	startIdx := valIdx
-	if valIdx > 0 && n.block[valIdx-1].Typ == dataTypeLineBreak {
-		startIdx = valIdx - 1
+	for startIdx > 0 && isInlineComment(n.block[startIdx-1]) {
+		startIdx--
+	}
+	if startIdx == 0 || n.block[startIdx-1].Typ != dataTypeComma {
+		startIdx = valIdx
+	}
+	// include the preceding LB in the deletion range (preserves multi-line formatting)
+	lbIncluded := startIdx > 0 && n.block[startIdx-1].Typ == dataTypeLineBreak
+	if lbIncluded {
+		startIdx--
 	}
-	// consume the trailing comma (if any)
+	// consume inline comments before the comma and the trailing comma (if any), e.g. 2 /* two */,
 	endIdx := valIdx + 1
+	for endIdx < len(n.block) && isInlineComment(n.block[endIdx]) {
+		endIdx++
+	}
+	hasComma := false
 	for endIdx < len(n.block) && n.block[endIdx].Typ == dataTypeComma {
 		endIdx++
+		hasComma = true
 	}
-	// if nothing was consumed after val (only EndFlag follows), also delete preceding comma
-	if endIdx == valIdx+1 && startIdx == valIdx && startIdx > 0 && n.block[startIdx-1].Typ == dataTypeComma {
-		startIdx--
+	// a same-line comment ending the element's line is deleted with it, keeping a single line break
+	if endIdx < len(n.block) && n.block[endIdx].Typ == dataTypeCommentLine && endsWithLineBreak(n.block[endIdx]) {
+		endIdx++
+		if lbIncluded {
+			startIdx++
+			lbIncluded = false
+		}
+	}
+	// the element starts a line after a comment that ends with a line break: delete its own line break instead
+	if !lbIncluded && startIdx > 0 && endsWithLineBreak(n.block[startIdx-1]) &&
+		endIdx < len(n.block) && n.block[endIdx].Typ == dataTypeLineBreak {
+		endIdx++
+	}
+	// the last element without a trailing comma: the comma of the now-last element is deleted as well
+	commaIdx := -1
+	if !hasComma {
+		for i := startIdx - 1; i >= 0 && n.block[i].Is(dataTypeComment|dataTypeCommentLine|dataTypeLineBreak|dataTypeComma); i-- {
+			if n.block[i].Typ == dataTypeComma {
+				commaIdx = i
+				break
+			}
+		}
 	}
 	n.block = append(n.block[:startIdx], n.block[endIdx:]...)
+	if commaIdx >= 0 {
+		n.block = append(n.block[:commaIdx], n.block[commaIdx+1:]...)
+	}
 	// re-index: rename keys > deletedIdx by decrementing
 	deletedIdx, _ := strconv.Atoi(idxStr)
 	newChildren := make(map[string]*Node, len(n.children))
@@ -936,6 +1986,108 @@ func (n *Node) deleteArrayNode(idxStr string) *Node {
 	return n
 }
 
+// Clone 返回节点的深拷贝，对拷贝的修改不会影响原节点
+func (n *Node) Clone() *Node {
+	return n.clone()
+}
+
+// Extract 获取path对应的子树，返回与原文档完全独立的深拷贝，可作为单独的文档输出。
+// 拷贝不引用原文档的任何内存
+func (n *Node) Extract(path string) *Node {
+	node := n.Get(path)
+	if n.err != nil {
+		return &Node{err: n.err}
+	}
+	if node.Type() == None { // 未找到
+		return &Node{}
+	}
+	c := node.clone()
+	var opts *ParseOptions
+	if node.opts != nil {
+		o := *node.opts
+		opts = &o
+	}
+	c.detach(opts, "", c.offset)
+	return c
+}
+
+// GetCopy 获取path对应的子树的深拷贝，拷贝与原节点之间没有共享的可变状态，可以在不同的goroutine中分别修改。
+// 与 Extract 不同，拷贝中的错误位置与 SourceLine 仍相对于原文档。
+// 查找只读取原节点：path途经的未解析节点会先拷贝再解析，原节点不会被修改，因此可以在多个goroutine中
+// 并发调用（期间不能有其他修改原节点的操作）。原节点未解析时每次调用都会重新解析途经的节点
+func (n *Node) GetCopy(path string) *Node {
+	node, private := n, false
+	for _, nodePath := range parsePath(path).PathNoe {
+		if !node.parsed && !private { // 在私有的拷贝上解析，其子节点也都属于该拷贝
+			node, private = node.clone(), true
+		}
+		if node.parse().Error() != nil {
+			return &Node{err: node.err}
+		}
+		child, ok := node.children[nodePath]
+		if !ok {
+			return &Node{}
+		}
+		node = child
+	}
+	c := node
+	if !private {
+		c = node.clone()
+	}
+	c.parse()
+	if c.opts != nil {
+		opts := *c.opts
+		c.walk(nil, func(_ []string, node *Node) bool {
+			node.opts = &opts
+			return node.parsed // 未解析的节点没有子节点
+		})
+	}
+	return c
+}
+
+// detach 复制节点引用的所有字符串，使节点不再共享原文档的底层内存。
+// 节点以src为新的原始文档，位置相对base重新计算，src为空时使用节点自身的raw
+func (n *Node) detach(opts *ParseOptions, src string, base int) {
+	n.raw, n.val, n.opts = strings.Clone(n.raw), strings.Clone(n.val), opts
+	if src == "" {
+		src = n.raw
+	}
+	n.src, n.offset = src, n.offset-base
+	for i := range n.block {
+		n.block[i].Val = strings.Clone(n.block[i].Val)
+		n.block[i].Key = strings.Clone(n.block[i].Key)
+	}
+	for _, child := range n.children {
+		child.detach(opts, src, base)
+	}
+}
+
+// clone 深拷贝节点，解析出的block与子节点均不与原节点共享
+func (n *Node) clone() *Node {
+	c := *n
+	c.block = append([]dataBlock(nil), n.block...)
+	c.errs = append([]ParseError(nil), n.errs...)
+	c.shadowed = append([]string(nil), n.shadowed...)
+	if n.children != nil {
+		c.children = make(map[string]*Node, len(n.children))
+		for key, child := range n.children {
+			c.children[key] = child.clone()
+			c.children[key].parent = &c
+		}
+	}
+	return &c
+}
+
+// replaceWith 用src替换节点的内容，保留节点的解析选项与父节点，src的子节点改为挂在当前节点下
+func (n *Node) replaceWith(src *Node) {
+	opts, parent := n.opts, n.parent
+	*n = *src
+	n.opts, n.parent = opts, parent
+	for _, child := range n.children {
+		child.parent = n
+	}
+}
+
 func buildObjectNode() *Node {
 	return &Node{
 		parsed:   true,
@@ -948,6 +2100,18 @@ func buildObjectNode() *Node {
 	}
 }
 
+func buildArrayNode() *Node {
+	return &Node{
+		parsed:   true,
+		typ:      Array,
+		children: map[string]*Node{},
+		block: []dataBlock{
+			{Typ: dataTypeStartFlag},
+			{Typ: dataTypeEndFlag},
+		},
+	}
+}
+
 type parsedPath struct {
 	Root    bool
 	PathNoe []string
@@ -970,6 +2134,8 @@ func parsePath(path string) parsedPath {
 	return pPath
 }
 
+// ForEach 按文档顺序遍历对象的成员或数组的元素，数组元素的key为其下标，如 "0"、"1"；标量以空key调用一次。
+// iterator返回false时停止遍历
 func (n *Node) ForEach(iterator func(key string, value *Node) bool) {
 	if n.parse().Error() != nil {
 		return
@@ -999,3 +2165,29 @@ func (n *Node) ForEach(iterator func(key string, value *Node) bool) {
 		iterator("", n)
 	}
 }
+
+// SortedKeys 返回对象按字典序排序后的key，不修改文档中key的顺序；非对象返回nil
+func (n *Node) SortedKeys() []string {
+	if n.parse().Error() != nil || n.typ != Object {
+		return nil
+	}
+	keys := n.orderedKeys()
+	sort.Strings(keys)
+	return keys
+}
+
+// ForEachSorted 与ForEach相同，但对象按key的字典序遍历，不修改文档中key的顺序
+func (n *Node) ForEachSorted(iterator func(key string, value *Node) bool) {
+	if n.parse().Error() != nil {
+		return
+	}
+	if n.typ != Object {
+		n.ForEach(iterator)
+		return
+	}
+	for _, key := range n.SortedKeys() {
+		if !iterator(key, n.children[key]) {
+			return
+		}
+	}
+}
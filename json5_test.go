@@ -1,7 +1,14 @@
 package pjson5
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -91,6 +98,18 @@ func TestArray_ForEach(t *testing.T) {
 		t.Fatalf("expected 4 iterations, got %d", len(keys))
 	}
 	log.Printf("ForEach keys=%v vals=%v", keys, vals)
+
+	// 修改后按文档顺序遍历，返回false时停止
+	nums := node.Delete("nums.0").Append("nums", 5).Get("nums")
+	keys, vals = nil, nil
+	nums.ForEach(func(key string, value *Node) bool {
+		keys = append(keys, key)
+		vals = append(vals, value.Value())
+		return key != "2"
+	})
+	if !reflect.DeepEqual(keys, []string{"0", "1", "2"}) || !reflect.DeepEqual(vals, []string{"2", "3", "4"}) {
+		t.Fatalf("ForEach() keys=%v vals=%v, want keys [0 1 2] vals [2 3 4]", keys, vals)
+	}
 }
 
 func TestArray_Set(t *testing.T) {
@@ -279,6 +298,42 @@ func TestArray_Delete(t *testing.T) {
 	log.Println("delete result:", node.Pretty())
 }
 
+func TestArray_DeleteElement(t *testing.T) {
+	multiLine := "{a: [\n  1, // one\n  2, // two\n  3 // three\n]}"
+	tests := []struct {
+		name string
+		data string
+		path string
+		want string
+	}{
+		{name: "middle", data: `{a: [1, 2, 3]}`, path: "a.1", want: "{   a: [ 1, 3  ]}"},
+		{name: "last", data: `{a: [1, 2, /*x*/ 3]}`, path: "a.2", want: "{   a: [ 1, 2  ]}"},
+		{name: "negative", data: `{a: [1, 2, 3]}`, path: "a.-1", want: "{   a: [ 1, 2  ]}"},
+		{name: "only", data: `{a: [1]}`, path: "a.0", want: "{   a: [   ]}"},
+		{name: "comment_before_comma", data: `{a: [1, 2 /* two */, 3]}`, path: "a.1", want: "{   a: [ 1, 3  ]}"},
+		{name: "multi_line_middle", data: multiLine, path: "a.1", want: "{   a: [\n    1, // one\n    3 // three\n  ]}"},
+		{name: "multi_line_last", data: multiLine, path: "a.2", want: "{   a: [\n    1, // one\n    2 // two\n  ]}"},
+		{
+			name: "trailing_comma_kept",
+			data: "{a: [\n  1,\n  2,\n]}",
+			path: "a.1",
+			want: "{   a: [\n    1, \n  ]}",
+		},
+		{name: "out_of_range", data: `{a: [1]}`, path: "a.3", want: "{   a: [ 1  ]}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.data).Delete(tt.path)
+			if node.Error() != nil {
+				t.Fatal("Delete() error:", node.Error())
+			}
+			if got := node.Pretty(); got != tt.want {
+				t.Fatalf("Delete() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestArray_Pretty(t *testing.T) {
 	node := New(rawArrayJson)
 	// parse array explicitly to trigger rebuild
@@ -287,6 +342,29 @@ func TestArray_Pretty(t *testing.T) {
 	log.Println("pretty:", pretty)
 }
 
+func TestArray_ElementType(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantTyp  Type
+		wantSame bool
+	}{
+		{name: "numbers", raw: "[1, 2, 3]", wantTyp: Number, wantSame: true},
+		{name: "mixed", raw: `[1, "x"]`, wantTyp: None, wantSame: false},
+		{name: "empty", raw: "[]", wantTyp: None, wantSame: true},
+		{name: "objects", raw: `[{"a": 1}, /* c */ {"b": 2},]`, wantTyp: Object, wantSame: true},
+		{name: "not_array", raw: `{"a": 1}`, wantTyp: None, wantSame: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, same := New(tt.raw).ElementType()
+			if typ != tt.wantTyp || same != tt.wantSame {
+				t.Fatalf("ElementType() = (%v, %v), want (%v, %v)", typ, same, tt.wantTyp, tt.wantSame)
+			}
+		})
+	}
+}
+
 var rawJson = `{ // 首行注释
   "number_key": 2,// 人数
   "string_key": /*key中注释*/"www.com",// 字符串类型后注释
@@ -519,6 +597,75 @@ func TestNode_SetMulti(t *testing.T) {
 	}
 }
 
+func TestNode_SetRawValue(t *testing.T) {
+	node := New(rawJson)
+	if err := node.Get("map_key.val").SetRawValue("0x10 /* hex */"); err != nil {
+		t.Fatal("SetRawValue() error:", err)
+	}
+	if err := node.Get("array_key.1").SetRawValue(`{b: 'x'}`); err != nil {
+		t.Fatal("SetRawValue() error:", err)
+	}
+	pretty := node.Pretty()
+	for _, want := range []string{
+		`"array_key": [ 1, {       b: 'x'    }, 3, 4  ], // 数组类型`,
+		`"val": 0x10 /* hex */, // val`,
+	} {
+		if !strings.Contains(pretty, want) {
+			t.Fatalf("SetRawValue() = %s, want it to contain %q", pretty, want)
+		}
+	}
+	if got := node.Get("array_key.1.b").Value(); got != "'x'" {
+		t.Fatalf("array_key.1.b = %q, want 'x'", got)
+	}
+
+	if err := node.Get("number_key").SetRawValue(`{a: `); err == nil {
+		t.Fatal("expected error for invalid raw value")
+	}
+	if got := node.Get("number_key").Value(); got != "2" {
+		t.Fatalf("number_key = %q after invalid SetRawValue, want 2", got)
+	}
+	missing := node.Get("not_exist")
+	if err := missing.SetRawValue("1"); !errors.Is(err, ErrPathNotFound) || !errors.Is(missing.Error(), ErrPathNotFound) {
+		t.Fatalf("SetRawValue() on a missing node error = %v, node error = %v", err, missing.Error())
+	}
+	if node.Get("not_exist").IsExist() {
+		t.Fatal("expected missing node to stay missing")
+	}
+}
+
+func TestNode_SetArrayIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr string
+	}{
+		{name: "middle", path: "a.1", want: "{   a: [ 1, 99, /*x*/3  ]}"},
+		{name: "after_comment", path: "a.2", want: "{   a: [ 1, 2, /*x*/99  ]}"},
+		{name: "negative", path: "a.-1", want: "{   a: [ 1, 2, /*x*/99  ]}"},
+		{name: "append", path: "a.3", want: "{   a: [ 1, 2, /*x*/3, 99\n  ]}"},
+		{name: "out_of_range", path: "a.5", wantErr: "array index out of range: 5 (length 3)"},
+		{name: "negative_out_of_range", path: "a.-4", wantErr: "array index out of range: -4 (length 3)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(`{a: [1, 2, /*x*/ 3]}`).Set(tt.path, 99)
+			if tt.wantErr != "" {
+				if node.Error() == nil || node.Error().Error() != tt.wantErr {
+					t.Fatalf("Set() error = %v, want %q", node.Error(), tt.wantErr)
+				}
+				return
+			}
+			if node.Error() != nil {
+				t.Fatal("Set() error:", node.Error())
+			}
+			if got := node.Pretty(); got != tt.want {
+				t.Fatalf("Set() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // ==================== JSON5 Feature Tests ====================
 
 // JSON5: unquoted keys
@@ -852,3 +999,518 @@ func TestNode_UrlInValueNotTreatedAsComment(t *testing.T) {
 		t.Fatal("after_widget.k should exist")
 	}
 }
+
+func TestNode_UnmarshalJSON(t *testing.T) {
+	var conf struct {
+		Name  string `json:"name"`
+		Extra *Node  `json:"extra"`
+	}
+	data := `{"name": "svc", "extra": {"limits": {"ports": [80, 443]}, "debug": true}}`
+	if err := json.Unmarshal([]byte(data), &conf); err != nil {
+		t.Fatal("unmarshal error:", err)
+	}
+	if conf.Name != "svc" {
+		t.Fatalf("expected name=svc, got %q", conf.Name)
+	}
+	if conf.Extra == nil || conf.Extra.parsed {
+		t.Fatal("expected extra to be captured lazily")
+	}
+	if v := conf.Extra.Get("limits.ports.1").Value(); v != "443" {
+		t.Fatalf("expected extra.limits.ports[1]=443, got %q", v)
+	}
+	if v := conf.Extra.Get("debug").Type(); v != Boolean {
+		t.Fatalf("expected extra.debug to be Boolean, got %v", v)
+	}
+}
+
+func TestNode_DeleteKeepComments(t *testing.T) {
+	node := New(rawJson).DeleteKeepComments("map_key")
+	if node.Error() != nil {
+		t.Fatal("delete error:", node.Error())
+	}
+	pretty := node.Pretty()
+	if !strings.Contains(pretty, "// 字典类型行注释") {
+		t.Fatalf("expected leading comment to be kept:\n%s", pretty)
+	}
+	if node.Exists("map_key") || strings.Contains(pretty, "map_key") {
+		t.Fatalf("expected map_key to be deleted:\n%s", pretty)
+	}
+	if reparsed := New(pretty); !reparsed.Exists("array_key") || reparsed.Error() != nil {
+		t.Fatalf("expected result to stay valid, err=%v", reparsed.Error())
+	}
+
+	// 后置的同行注释仍随key一起删除
+	node = New(rawJson).DeleteKeepComments("number_key")
+	if pretty = node.Pretty(); strings.Contains(pretty, "// 人数") {
+		t.Fatalf("expected trailing comment to be deleted with the key:\n%s", pretty)
+	}
+
+	// 默认的Delete会一并删除前置注释
+	node = New(rawJson).Delete("map_key")
+	if pretty = node.Pretty(); strings.Contains(pretty, "// 字典类型行注释") {
+		t.Fatalf("expected Delete to remove the leading comment:\n%s", pretty)
+	}
+}
+
+func TestNode_EscapedKeys(t *testing.T) {
+	input := `{
+  "a\tb": 1,
+  "x\u0041y": 2,
+  'it\'s': 3,
+  "q\"k": 4,
+  smile: 5,
+}`
+	node := New(input)
+	tests := map[string]string{"a\tb": "1", "xAy": "2", "it's": "3", `q"k`: "4", "smile": "5"}
+	for key, want := range tests {
+		if v := node.Get(key).Value(); v != want {
+			t.Fatalf("expected %q=%s, got %q (err=%v)", key, want, v, node.Error())
+		}
+	}
+	var keys []string
+	node.ForEach(func(key string, _ *Node) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 5 || keys[0] != "a\tb" || keys[3] != `q"k` {
+		t.Fatalf("unexpected ForEach keys: %q", keys)
+	}
+	// 原始的转义写法保持不变，新增的key按JSON规则转义
+	node.Delete("it's").Set("c\td", true)
+	pretty := node.Pretty()
+	if !strings.Contains(pretty, `"a\tb": 1`) || !strings.Contains(pretty, `"c\td": true`) || strings.Contains(pretty, "it") {
+		t.Fatalf("unexpected pretty output:\n%s", pretty)
+	}
+	if v := New(pretty).Get("c\td").Value(); v != "true" {
+		t.Fatalf("expected reparsed c\\td=true, got %q", v)
+	}
+}
+
+func TestNode_Touch(t *testing.T) {
+	node := New(rawJson)
+	node.Touch("number_key").Touch("placeholder").Touch("map_key.owner").Touch("scaffold.level_1")
+	if node.Error() != nil {
+		t.Fatal("touch error:", node.Error())
+	}
+	if v := node.Get("number_key").Value(); v != "2" {
+		t.Fatalf("expected existing number_key to be kept, got %q", v)
+	}
+	for _, path := range []string{"placeholder", "map_key.owner", "scaffold.level_1"} {
+		if typ := node.Get(path).Type(); typ != Null {
+			t.Fatalf("expected %s to be null, got %v", path, typ)
+		}
+	}
+	log.Println("touch result:", node.Pretty())
+}
+
+func TestNode_Extract(t *testing.T) {
+	node := New(rawJson)
+	sub := node.Extract("map_key")
+	if sub.Error() != nil {
+		t.Fatal("extract error:", sub.Error())
+	}
+	if v := sub.Get("name").Value(); v != `"This is name"` {
+		t.Fatalf("unexpected name in extracted node: %q", v)
+	}
+	sub.Set("name", "changed").Delete("val")
+	if v := node.Get("map_key.name").Value(); v != `"This is name"` {
+		t.Fatalf("expected original to be unchanged, got %q", v)
+	}
+	if !node.Exists("map_key.val") {
+		t.Fatal("expected map_key.val to stay in original")
+	}
+	if v := New(sub.Pretty()).Get("name").Value(); v != `"changed"` {
+		t.Fatalf("expected extracted document to be standalone, got %q", v)
+	}
+	if node.Extract("not_exist").IsExist() {
+		t.Fatal("expected missing path to extract nothing")
+	}
+	log.Println("extract result:", sub.Pretty())
+
+	src := New(`{"a": {"b": 1}}`)
+	root := src.Extract("")
+	root.Set("a.b", 2).Set("c", 3)
+	if got := src.Pretty(); got != `{   "a": {"b": 1}}` {
+		t.Fatalf("expected source to be unchanged after mutating extracted root, got %q", got)
+	}
+	if got := root.Get("a.b").Value(); got != "2" {
+		t.Fatalf("expected extracted root to be modified, got %q", got)
+	}
+}
+
+func TestNode_CanSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		val     any
+		wantErr bool
+	}{
+		{name: "overwrite", path: "map_key.val", val: 1},
+		{name: "create_intermediate", path: "new_map.sub.key", val: "v"},
+		{name: "append_array", path: "array_key.4", val: 5},
+		{name: "scalar_intermediate", path: "number_key.sub", val: 1, wantErr: true},
+		{name: "array_out_of_range", path: "array_key.10", val: 1, wantErr: true},
+		{name: "unmarshalable", path: "map_key.val", val: make(chan int), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(rawJson).Parse()
+			before := node.Pretty()
+			if err := node.CanSet(tt.path, tt.val); (err != nil) != tt.wantErr {
+				t.Fatalf("CanSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if node.Error() != nil || node.Pretty() != before {
+				t.Fatalf("expected node to be unchanged, err=%v", node.Error())
+			}
+			if err := node.Set(tt.path, tt.val).Error(); (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNode_GetOrCreateArray(t *testing.T) {
+	node := New(rawJson)
+	arr := node.GetOrCreateArray("array_key")
+	if node.Error() != nil || arr.Len() != 4 {
+		t.Fatalf("expected existing array, len=%d err=%v", arr.Len(), node.Error())
+	}
+	created := node.GetOrCreateArray("map_key.tags")
+	if node.Error() != nil || !created.IsArray() || created.Len() != 0 {
+		t.Fatalf("expected empty array to be created, err=%v", node.Error())
+	}
+	node.Set("map_key.tags.0", "a").Set("map_key.tags.1", "b")
+	if v := New(node.Pretty()).Get("map_key.tags.1").Value(); v != `"b"` {
+		t.Fatalf("expected created array to be appendable, got %q\n%s", v, node.Pretty())
+	}
+	if bad := node.GetOrCreateArray("map_key.name"); bad.Error() == nil || node.Error() == nil {
+		t.Fatal("expected error for non-array path")
+	}
+	if v := node.children["map_key"].children["name"].Value(); v != `"This is name"` {
+		t.Fatalf("expected non-array value to be kept, got %q", v)
+	}
+}
+
+func TestNode_SortedKeys(t *testing.T) {
+	node := New(rawJson)
+	want := []string{"array_key", "map_key", "number_key", "string_key"}
+	if got := node.SortedKeys(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("SortedKeys() = %v, want %v", got, want)
+	}
+	var visited []string
+	node.Get("map_key").ForEachSorted(func(key string, value *Node) bool {
+		visited = append(visited, key+"="+value.Value())
+		return true
+	})
+	if got := strings.Join(visited, ","); got != `data_list=[5000],name="This is name",val=60000` {
+		t.Fatalf("unexpected sorted iteration: %s", got)
+	}
+	if strings.Index(node.Pretty(), `"number_key"`) > strings.Index(node.Pretty(), `"array_key"`) {
+		t.Fatal("expected document order to be unchanged")
+	}
+	if keys := node.Get("array_key").SortedKeys(); keys != nil {
+		t.Fatalf("expected nil for array, got %v", keys)
+	}
+}
+
+func TestNode_Lookup(t *testing.T) {
+	node := New(rawJson)
+	tests := []struct {
+		path   string
+		wantOk bool
+		want   string
+	}{
+		{path: "number_key", wantOk: true, want: "2"},
+		{path: "map_key.data_list.0", wantOk: true, want: "5000"},
+		{path: "array_key.2", wantOk: true, want: "3"},
+		{path: "array_key.-1", wantOk: true, want: "4"},
+		{path: "array_key.-4", wantOk: true, want: "1"},
+		{path: "array_key.-5", wantOk: false},
+		{path: "array_key.4", wantOk: false},
+		{path: "map_key.data_list.-1", wantOk: true, want: "5000"},
+		{path: "map_key.missing", wantOk: false},
+		{path: "number_key.sub", wantOk: false},
+	}
+	for _, tt := range tests {
+		got, ok := node.Lookup(tt.path)
+		if ok != tt.wantOk {
+			t.Fatalf("Lookup(%q) ok = %v, want %v", tt.path, ok, tt.wantOk)
+		}
+		if ok && got.Value() != tt.want {
+			t.Fatalf("Lookup(%q) = %q, want %q", tt.path, got.Value(), tt.want)
+		}
+	}
+	if _, ok := New(`{"a": 1`).Lookup("a"); ok {
+		t.Fatal("expected lookup on invalid document to fail")
+	}
+}
+
+func TestNode_Depth(t *testing.T) {
+	node := New(rawJson)
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "", want: 0},
+		{path: "$", want: 0},
+		{path: "number_key", want: 1},
+		{path: "$.map_key.name", want: 2},
+		{path: "map_key.data_list.0", want: 3},
+		{path: "map_key.missing", want: -1},
+		{path: "array_key.9", want: -1},
+	}
+	for _, tt := range tests {
+		if got := node.Depth(tt.path); got != tt.want {
+			t.Fatalf("Depth(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNode_SourceLine(t *testing.T) {
+	node := New(rawJson)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: "{ // 首行注释"},
+		{path: "number_key", want: `  "number_key": 2,// 人数`},
+		{path: "string_key", want: `  "string_key": /*key中注释*/"www.com",// 字符串类型后注释`},
+		{path: "array_key.2", want: `  "array_key": [1, 2, 3, 4], // 数组类型`},
+		{path: "map_key.val", want: "\t\"val\": 60000, // val"},
+		{path: "map_key.data_list.0", want: `    "data_list": [5000],`},
+		{path: "map_key.missing", want: ""},
+	}
+	for _, tt := range tests {
+		if got := node.SourceLine(tt.path); got != tt.want {
+			t.Fatalf("SourceLine(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	crlf := New("{\r\n  \"a\": 1,\r\n  \"b\": 2\r\n}")
+	if got := crlf.SourceLine("b"); got != `  "b": 2` {
+		t.Fatalf("SourceLine(b) = %q", got)
+	}
+}
+
+func TestNode_Extend(t *testing.T) {
+	node := New(`{"cors": {"origins": ["a.com"]}}`)
+	node.Extend("cors.origins", "b.com", "c.com").Append("cors.origins", "d.com")
+	if got := node.Pretty(); got != "{   \"cors\": {     \"origins\": [ \"a.com\", \"b.com\", \n\"c.com\", \n\"d.com\"\n    ]  }}" {
+		t.Fatalf("Extend() = %q, err=%v", got, node.Error())
+	}
+	node = New("{\n  \"list\": [\n    1,\n  ],\n}").Extend("list", 2, 3)
+	if got := node.Pretty(); got != "{\n  \"list\": [\n    1, \n    2, \n    3\n  ], \n}" {
+		t.Fatalf("Extend() multi-line = %q", got)
+	}
+	node = New(`{}`).Extend("tags", "x", map[string]int{"y": 1})
+	if v := node.Get("tags.1.y").Value(); v != "1" || node.Get("tags").Len() != 2 {
+		t.Fatalf("Extend() on missing path = %q", node.Pretty())
+	}
+	node = New(`{"a": [1], "s": "x"}`).Parse()
+	if node.Extend("a", 2, func() {}).Error() == nil || node.children["a"].Len() != 1 {
+		t.Fatal("expected marshal error without partial append")
+	}
+	if New(`{"s": "x"}`).Extend("s", 1).Error() == nil {
+		t.Fatal("expected error for non-array path")
+	}
+}
+
+func TestNode_SetBeforeTrailingComments(t *testing.T) {
+	node := New("{\n  \"a\": 1, // a\n  // 尾部注释\n  /* 块注释 */\n}")
+	node.Set("b", 2)
+	want := "{\n  \"a\": 1, // a\n  \"b\": 2\n  // 尾部注释\n  /* 块注释 */\n}"
+	if got := node.Pretty(); got != want {
+		t.Fatalf("Set() = %q, want %q", got, want)
+	}
+	node = New("{\n  // 仅有注释\n}").Set("a", 1)
+	if got, want := node.Pretty(), "{\n  \"a\": 1\n  // 仅有注释\n}"; got != want {
+		t.Fatalf("Set() = %q, want %q", got, want)
+	}
+}
+
+func TestNode_ArrayRoot(t *testing.T) {
+	node := New("// 列表\n[\n  1, // one\n  {\"a\": 2},\n  // 结尾\n]\n")
+	if v := node.Get("1.a").Value(); v != "2" || !node.Exists("0") || node.Depth("1.a") != 2 {
+		t.Fatalf("Get() on array root = %q, err=%v", v, node.Error())
+	}
+	node.Set("0", "x").Set("1.b", 3).Set("2", []int{4}).Set("3.k", true)
+	if node.Error() != nil {
+		t.Fatal("Set() on array root error:", node.Error())
+	}
+	want := "// 列表\n[\n\"x\", // one\n{     \"a\": 2,\n    \"b\": 3\n  }, \n[4], \n{     \"k\": true\n  }\n// 结尾\n]\n"
+	if got := node.Pretty(); got != want {
+		t.Fatalf("Set() = %q, want %q", got, want)
+	}
+	node.Delete("1").Delete("0")
+	want = "// 列表\n[\n[4], \n{     \"k\": true\n  }\n// 结尾\n]\n"
+	if got := node.Pretty(); got != want {
+		t.Fatalf("Delete() = %q, want %q", got, want)
+	}
+	var keys []string
+	node.ForEach(func(key string, value *Node) bool {
+		keys = append(keys, key+"="+value.Pretty())
+		return true
+	})
+	if got := strings.Join(keys, ","); got != "0=[4],1={   \"k\": true\n}" {
+		t.Fatalf("ForEach() = %s", got)
+	}
+	if err := node.Set("5", 1).Error(); err == nil {
+		t.Fatal("expected out of range error")
+	}
+}
+
+func TestNode_GetCopy(t *testing.T) {
+	node := New(rawJson) // 未解析的节点上并发首次调用，需使用 go test -race 检查
+	var wg sync.WaitGroup
+	results := make([]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			section := node.GetCopy("map_key")
+			section.Set("val", i).Set("worker", i)
+			results[i] = section.Get("val").Value() + "," + section.Get("worker").Value()
+		}(i)
+	}
+	wg.Wait()
+	for i, got := range results {
+		if want := strconv.Itoa(i) + "," + strconv.Itoa(i); got != want {
+			t.Fatalf("worker %d got %q, want %q", i, got, want)
+		}
+	}
+	if v := node.Get("map_key.val").Value(); v != "60000" || node.Exists("map_key.worker") {
+		t.Fatalf("expected original to be unchanged, val=%q", v)
+	}
+	if c := node.GetCopy("map_key.missing"); c.Error() != nil || c.IsExist() {
+		t.Fatalf("expected empty node for missing path, err=%v", c.Error())
+	}
+	if line := node.GetCopy("map_key").SourceLine("name"); !strings.Contains(line, `"name": "This is name"`) {
+		t.Fatalf("expected positions relative to the original document, got %q", line)
+	}
+}
+
+func TestNode_SetFloat(t *testing.T) {
+	tests := []struct {
+		v      float64
+		format byte
+		prec   int
+		want   string
+	}{
+		{v: 3.14159, format: 'f', prec: 2, want: "3.14"},
+		{v: 2, format: 'f', prec: 2, want: "2.00"},
+		{v: 1234.5, format: 'e', prec: 3, want: "1.234e+03"},
+		{v: 1.0 / 3, format: 'g', prec: 4, want: "0.3333"},
+		{v: math.Inf(-1), format: 'f', prec: 2, want: "-Infinity"},
+		{v: math.NaN(), format: 'f', prec: 2, want: "NaN"},
+	}
+	for _, tt := range tests {
+		node := New(rawJson).SetFloat("map_key.val", tt.v, tt.format, tt.prec)
+		if got := New(node.Pretty()).Get("map_key.val"); got.Value() != tt.want || got.Type() != Number {
+			t.Fatalf("SetFloat(%v, %q, %d) = %q, want %q", tt.v, tt.format, tt.prec, got.Value(), tt.want)
+		}
+	}
+	if !strings.Contains(New(rawJson).SetFloat("number_key", 1.5, 'f', 1).Pretty(), `"number_key": 1.5, // 人数`) {
+		t.Fatal("expected comments to be kept")
+	}
+	if err := New(rawJson).SetFloat("number_key", 1, 'x', -1).Error(); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestNode_SetOrdered(t *testing.T) {
+	node := New(`{"name": "svc"}`).SetOrdered("server", []KV{
+		{Key: "port", Value: 8080},
+		{Key: "host", Value: "localhost"},
+		{Key: "tls", Value: []KV{{Key: "enabled", Value: true}, {Key: "cert", Value: "a.pem"}}},
+		{Key: "alias", Value: map[string]int{"b": 2, "a": 1}},
+	})
+	if node.Error() != nil {
+		t.Fatal("SetOrdered() error:", node.Error())
+	}
+	want := `{   "name": "svc",
+  "server": {"port": 8080, "host": "localhost", "tls": {"enabled": true, "cert": "a.pem"}, "alias": {"a":1,"b":2}}
+}`
+	if got := node.Pretty(); got != want {
+		t.Fatalf("SetOrdered() = %s, want %s", got, want)
+	}
+	if v := node.Get("server.tls.cert").Value(); v != `"a.pem"` {
+		t.Fatalf("Get(server.tls.cert) = %s", v)
+	}
+	if err := New(`{}`).SetOrdered("a", []KV{{Key: "x", Value: 1}, {Key: "x", Value: 2}}).Error(); err == nil {
+		t.Fatal("expected error for duplicate keys")
+	}
+	if err := New(`{}`).SetOrdered("a", []KV{{Key: "x", Value: func() {}}}).Error(); err == nil {
+		t.Fatal("expected marshal error")
+	}
+}
+
+func TestNode_Require(t *testing.T) {
+	node := New(`{a: 1, b: {c: null, d: "x"}, e: [0]}`)
+	if err := node.Require("a", "b.c", "b.d", "e.0"); err != nil {
+		t.Fatal("Require() error:", err)
+	}
+	err := node.Require("a", "x", "b.c", "b.y", "e.1")
+	var me *MissingKeysError
+	if !errors.As(err, &me) || !reflect.DeepEqual(me.Paths, []string{"x", "b.y", "e.1"}) {
+		t.Fatalf("Require() error = %v", err)
+	}
+	if err.Error() != "missing required keys: x, b.y, e.1" {
+		t.Fatalf("Require() message = %s", err.Error())
+	}
+	if err := node.RequireNonNull("a", "b.c", "z"); !errors.As(err, &me) || !reflect.DeepEqual(me.Paths, []string{"b.c", "z"}) {
+		t.Fatalf("RequireNonNull() error = %v", err)
+	}
+	if err := New(`{a: `).Require("a"); errors.As(err, &me) || err == nil {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestNode_RejectUnknownKeys(t *testing.T) {
+	node := New(`{name: 'a', port: 80, debug: true, tls: {cert: 'c', pin: 1}, routes: [{path: '/', extra: 1}]}`)
+	if err := node.RejectUnknownKeys("name", "port", "debug", "tls", "routes"); err != nil {
+		t.Fatal("RejectUnknownKeys() error:", err)
+	}
+	err := node.RejectUnknownKeys("name", "tls")
+	var ue *UnknownKeysError
+	if !errors.As(err, &ue) || !reflect.DeepEqual(ue.Paths, []string{"port", "debug", "routes"}) {
+		t.Fatalf("RejectUnknownKeys() error = %v", err)
+	}
+	if err.Error() != "unknown keys: port, debug, routes" {
+		t.Fatalf("RejectUnknownKeys() message = %s", err.Error())
+	}
+	err = node.RejectUnknownKeysRecursive("name", "port", "debug", "tls", "routes", "cert", "path")
+	if !errors.As(err, &ue) || !reflect.DeepEqual(ue.Paths, []string{"tls.pin", "routes.0.extra"}) {
+		t.Fatalf("RejectUnknownKeysRecursive() error = %v", err)
+	}
+	if err := New(`[1, {a: 1}]`).RejectUnknownKeys(); err != nil {
+		t.Fatalf("expected no error for array root, got %v", err)
+	}
+	if err := New(`{a: {b: }}`).RejectUnknownKeysRecursive("a"); err == nil || errors.As(err, &ue) {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestNode_IsScalar(t *testing.T) {
+	tests := []struct {
+		path          string
+		wantScalar    bool
+		wantContainer bool
+	}{
+		{path: "number_key", wantScalar: true},
+		{path: "string_key", wantScalar: true},
+		{path: "array_key", wantContainer: true},
+		{path: "map_key", wantContainer: true},
+		{path: "missing"},
+	}
+	node := New(rawJson)
+	for _, tt := range tests {
+		child := node.Get(tt.path)
+		if child.IsScalar() != tt.wantScalar || child.IsContainer() != tt.wantContainer {
+			t.Fatalf("%s: IsScalar() = %v, IsContainer() = %v", tt.path, child.IsScalar(), child.IsContainer())
+		}
+	}
+	for _, data := range []string{"null", "true", "'x'", "NaN"} {
+		if !New(data).IsScalar() {
+			t.Fatalf("expected %s to be scalar", data)
+		}
+	}
+}
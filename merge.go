@@ -0,0 +1,207 @@
+package pjson5
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Merge 将other深度合并到当前节点：两边都是对象的key递归合并，其余key使用other中的值覆盖或追加到末尾。
+// 当前节点原有的注释保持不变
+func (n *Node) Merge(other *Node) *Node {
+	return n.merge(other, false)
+}
+
+// MergeAdoptComments 与Merge相同，但被覆盖或新增的key同时采用other中该key的注释，替换原有注释
+func (n *Node) MergeAdoptComments(other *Node) *Node {
+	return n.merge(other, true)
+}
+
+func (n *Node) merge(other *Node, adoptComments bool) *Node {
+	if n.parse().Error() != nil {
+		return n
+	}
+	if other.parse().Error() != nil {
+		n.err = other.err
+		return n
+	}
+	if n.typ != Object || other.typ != Object { // 非对象时整体替换
+		n.replaceWith(other.clone())
+		return n
+	}
+	for _, key := range other.orderedKeys() {
+		src := other.children[key]
+		dst, ok := n.children[key]
+		if ok && dst.IsObject() && src.IsObject() {
+			if dst.merge(src, adoptComments); dst.err != nil {
+				n.err = dst.err
+				return n
+			}
+			continue
+		}
+		if ok {
+			n.children[key] = src.clone()
+			n.children[key].parent = n
+		} else if n.insertObjectNode(key, src.clone()); n.err != nil {
+			return n
+		}
+		if adoptComments {
+			leading, inner, trailing := other.keyComments(key)
+			n.setKeyComments(key, leading, inner, trailing)
+		}
+	}
+	return n
+}
+
+// MergeArrayBy 按keyField合并n与other中path对应的数组：两边keyField的值相等的对象元素使用 Merge 深度合并，
+// other中其余的元素按顺序追加到末尾。keyField按值比较，如 'a' 与 "a"、0x10 与 16 视为相等，
+// 没有keyField或不是对象的元素总是追加。other中不存在path时不做修改，n中不存在时创建该数组
+func (n *Node) MergeArrayBy(path, keyField string, other *Node) *Node {
+	src, ok := other.Lookup(path)
+	if other.err != nil {
+		n.err = other.err
+		return n
+	}
+	if !ok {
+		return n
+	}
+	if !src.IsArray() {
+		n.err = fmt.Errorf("path is not an array: %s", path)
+		return n
+	}
+	dst := n.GetOrCreateArray(path)
+	if n.err != nil {
+		return n
+	}
+	for _, elem := range src.orderedChildren() {
+		if match := dst.elementBy(keyField, elem); match != nil {
+			if match.merge(elem, false); match.err != nil {
+				n.err = match.err
+				return n
+			}
+			continue
+		}
+		child := elem.clone()
+		child.opts = dst.opts
+		if dst.insertArrayNode(child); dst.err != nil {
+			n.err = dst.err
+			return n
+		}
+	}
+	return n
+}
+
+// elementBy 返回数组中keyField的值与elem相同的第一个对象元素，elem没有keyField或keyField不是标量时返回nil
+func (n *Node) elementBy(keyField string, elem *Node) *Node {
+	if elem.parse().Error() != nil || elem.typ != Object {
+		return nil
+	}
+	id, ok := elem.children[keyField]
+	if !ok || id.parse().Error() != nil {
+		return nil
+	}
+	for _, child := range n.orderedChildren() {
+		if child.parse().Error() != nil || child.typ != Object {
+			continue
+		}
+		if v, ok := child.children[keyField]; ok && v.parse().Error() == nil && scalarEqual(v, id) {
+			return child
+		}
+	}
+	return nil
+}
+
+// Update 使用Go值v更新path对应的子树并保留注释：对象中两边都存在的key只替换值，v中新增的key追加到末尾，
+// 数组按下标更新，v中没有的key和元素保持不变。path不存在时等同于Set
+func (n *Node) Update(path string, v any) *Node {
+	return n.update(path, v, false)
+}
+
+// UpdatePrune 与Update相同，但同时删除v中不存在的key以及多余的数组元素
+func (n *Node) UpdatePrune(path string, v any) *Node {
+	return n.update(path, v, true)
+}
+
+func (n *Node) update(path string, v any, prune bool) *Node {
+	data, err := json.Marshal(v)
+	if err != nil {
+		n.err = fmt.Errorf("marshal data error:%w", err)
+		return n
+	}
+	target, ok := n.Lookup(path)
+	if n.err != nil {
+		return n
+	}
+	if !ok {
+		return n.SetString(path, string(data))
+	}
+	if target.updateFrom(New(string(data)), prune); target.err != nil {
+		n.err = target.err
+	}
+	return n
+}
+
+// updateFrom 将src的值写入当前节点，两边类型相同的对象与数组递归更新，其余情况整体替换
+func (n *Node) updateFrom(src *Node, prune bool) {
+	if n.parse().Error() != nil {
+		return
+	}
+	if src.parse().Error() != nil {
+		n.err = src.err
+		return
+	}
+	if n.typ != src.typ || (n.typ != Object && n.typ != Array) {
+		n.replaceWith(src)
+		return
+	}
+	if n.typ == Array {
+		n.updateArray(src, prune)
+		return
+	}
+	for _, key := range src.orderedKeys() {
+		child := src.children[key]
+		dst, ok := n.children[key]
+		if !ok {
+			child.opts = n.opts
+			if n.insertObjectNode(key, child); n.err != nil {
+				return
+			}
+			continue
+		}
+		if dst.updateFrom(child, prune); dst.err != nil {
+			n.err = dst.err
+			return
+		}
+	}
+	if !prune {
+		return
+	}
+	for _, key := range n.orderedKeys() {
+		if _, ok := src.children[key]; !ok {
+			n.deleteObjectNode(key, false)
+		}
+	}
+}
+
+func (n *Node) updateArray(src *Node, prune bool) {
+	for i := 0; i < len(src.children); i++ {
+		idx := strconv.Itoa(i)
+		child := src.children[idx]
+		dst, ok := n.children[idx]
+		if !ok {
+			child.opts = n.opts
+			n.insertArrayNode(child)
+			continue
+		}
+		if dst.updateFrom(child, prune); dst.err != nil {
+			n.err = dst.err
+			return
+		}
+	}
+	if !prune {
+		return
+	}
+	for i := len(n.children) - 1; i >= len(src.children); i-- {
+		n.deleteArrayNode(strconv.Itoa(i))
+	}
+}
@@ -0,0 +1,132 @@
+package pjson5
+
+import (
+	"strings"
+	"testing"
+)
+
+var rawDefaultsJson = `{
+  // 默认人数
+  "number_key": 5, // 默认值
+  "map_key": {
+    "val": 1, // 新val
+    "extra": true,
+  },
+  // 新增字段
+  "new_key": "x",
+}`
+
+func TestNode_Merge(t *testing.T) {
+	node := New(rawJson).Merge(New(rawDefaultsJson))
+	if node.Error() != nil {
+		t.Fatal("merge error:", node.Error())
+	}
+	want := map[string]string{
+		"number_key":    "5",
+		"string_key":    `"www.com"`,
+		"map_key.val":   "1",
+		"map_key.name":  `"This is name"`,
+		"map_key.extra": "true",
+		"new_key":       `"x"`,
+	}
+	for path, v := range want {
+		if got := node.Get(path).Value(); got != v {
+			t.Fatalf("expected %s=%s, got %q", path, v, got)
+		}
+	}
+	pretty := node.Pretty()
+	for _, comment := range []string{"// 人数", "// val", "// 字典类型行注释"} {
+		if !strings.Contains(pretty, comment) {
+			t.Fatalf("expected original comment %q to be kept:\n%s", comment, pretty)
+		}
+	}
+	for _, comment := range []string{"// 默认人数", "// 默认值", "// 新增字段"} {
+		if strings.Contains(pretty, comment) {
+			t.Fatalf("expected incoming comment %q to be ignored:\n%s", comment, pretty)
+		}
+	}
+	if reparsed := New(pretty); reparsed.Get("map_key.extra").Value() != "true" {
+		t.Fatalf("expected merged output to stay valid, err=%v\n%s", reparsed.Error(), pretty)
+	}
+}
+
+func TestNode_MergeAdoptComments(t *testing.T) {
+	node := New(rawJson).MergeAdoptComments(New(rawDefaultsJson))
+	if node.Error() != nil {
+		t.Fatal("merge error:", node.Error())
+	}
+	pretty := node.Pretty()
+	for _, want := range []string{
+		"// 默认人数\n  \"number_key\": 5, // 默认值\n",
+		"\"val\": 1, // 新val\n",
+		"// 新增字段\n  \"new_key\": \"x\"",
+		"// 字典类型行注释", // 递归合并的key保留原注释
+	} {
+		if !strings.Contains(pretty, want) {
+			t.Fatalf("expected %q in merged output:\n%s", want, pretty)
+		}
+	}
+	for _, comment := range []string{"// 人数", "// val\n"} {
+		if strings.Contains(pretty, comment) {
+			t.Fatalf("expected replaced comment %q to be removed:\n%s", comment, pretty)
+		}
+	}
+	if reparsed := New(pretty); reparsed.Get("new_key").Value() != `"x"` {
+		t.Fatalf("expected merged output to stay valid, err=%v\n%s", reparsed.Error(), pretty)
+	}
+}
+
+func TestNode_Update(t *testing.T) {
+	type config struct {
+		Name  string         `json:"name"`
+		Port  int            `json:"port"`
+		Tags  []string       `json:"tags"`
+		Extra map[string]int `json:"extra,omitempty"`
+	}
+	data := "{\n  // 服务名\n  \"name\": \"a\", // 名称\n  \"port\": 80, // 端口\n  \"tags\": [\"x\", \"y\", \"z\"],\n  \"old\": true, // 旧字段\n}"
+	v := config{Name: "b", Port: 8080, Tags: []string{"x", "w"}, Extra: map[string]int{"k": 1}}
+
+	got := New(data).Update("", v).Pretty()
+	want := "{\n  // 服务名\n  \"name\": \"b\", // 名称\n  \"port\": 8080, // 端口\n  \"tags\": [ \"x\", \"w\", \"z\"  ], \n  \"old\": true, // 旧字段\n  \"extra\": {\"k\":1}\n}"
+	if got != want {
+		t.Fatalf("Update() = %q, want %q", got, want)
+	}
+
+	got = New(data).UpdatePrune("", v).Pretty()
+	want = "{\n  // 服务名\n  \"name\": \"b\", // 名称\n  \"port\": 8080, // 端口\n  \"tags\": [ \"x\", \"w\"  ], \n  \"extra\": {\"k\":1}\n}"
+	if got != want {
+		t.Fatalf("UpdatePrune() = %q, want %q", got, want)
+	}
+
+	node := New(`{"a": 1}`).Update("b.c", []int{1})
+	if got := node.Get("b.c.0").Value(); got != "1" {
+		t.Fatalf("Update() on missing path, b.c.0 = %q, want 1", got)
+	}
+	if err := New(`{"a": 1}`).Update("a", func() {}).Error(); err == nil {
+		t.Fatal("Update() with unsupported value expected error")
+	}
+}
+
+func TestNode_MergeArrayBy(t *testing.T) {
+	data := "{\n  services: [\n    // 网关\n    {name: 'gw', port: 80, env: {a: 1}}, // 入口\n    {name: 'db', port: 5432},\n  ],\n}"
+	other := New(`{services: [{name: "db", port: 5433}, {name: 'cache'}, {name: 'gw', env: {b: 2}}, 'raw']}`)
+	got := New(data).MergeArrayBy("services", "name", other).Pretty()
+	want := "{\n  services: [\n    // 网关\n    {       name: 'gw',\n      port: 80,\n      env: {         a: 1,\n        \"b\": 2\n      }    }, // 入口\n    {       name: \"db\",\n      port: 5433    }, \n    {       name: 'cache'    }, \n    'raw'\n  ], \n}"
+	if got != want {
+		t.Fatalf("MergeArrayBy() = %q, want %q", got, want)
+	}
+
+	got = New(`{a: 1}`).MergeArrayBy("list", "id", New(`{list: [{id: 0x1}]}`)).Pretty()
+	if want = "{   a: 1,\n  \"list\": [ {       id: 0x1    }\n  ]\n}"; got != want {
+		t.Fatalf("MergeArrayBy() on missing path = %q, want %q", got, want)
+	}
+	if got = New(`{list: [{id: 1}]}`).MergeArrayBy("list", "id", New(`{}`)).Pretty(); got != "{list: [{id: 1}]}" {
+		t.Fatalf("MergeArrayBy() without other path = %q", got)
+	}
+	if err := New(`{list: 1}`).MergeArrayBy("list", "id", New(`{list: []}`)).Error(); err == nil {
+		t.Fatal("expected error when path is not an array")
+	}
+	if err := New(`{list: []}`).MergeArrayBy("list", "id", New(`{list: {}}`)).Error(); err == nil {
+		t.Fatal("expected error when other path is not an array")
+	}
+}
@@ -0,0 +1,52 @@
+package pjson5
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NonNormalizedKeys 返回key不是NFC规范形式的路径，按文档顺序排列。
+// 这样的key与其规范形式外观相同但并不相等（如 café 的两种写法），可能被用于同形异义攻击
+func (n *Node) NonNormalizedKeys() []string {
+	var paths []string
+	n.walk(nil, func(path []string, node *Node) bool {
+		if node.parse().Error() != nil || node.typ != Object {
+			return true
+		}
+		for _, key := range node.orderedKeys() {
+			if !norm.NFC.IsNormalString(key) {
+				paths = append(paths, joinPath(append(path[:len(path):len(path)], key)))
+			}
+		}
+		return true
+	})
+	return paths
+}
+
+// AmbiguousKeys 返回同一对象中忽略大小写并去掉首尾空白后相同的key分组，如 Port 与 port，用于合并多个来源前的检查。
+// 每组为这些key的完整路径，按文档顺序排列，只返回包含多个key的分组
+func (n *Node) AmbiguousKeys() [][]string {
+	var groups [][]string
+	n.walk(nil, func(path []string, node *Node) bool {
+		if node.parse().Error() != nil || node.typ != Object {
+			return true
+		}
+		var order []string
+		byForm := make(map[string][]string)
+		for _, key := range node.orderedKeys() {
+			form := strings.ToLower(strings.TrimSpace(key))
+			if _, ok := byForm[form]; !ok {
+				order = append(order, form)
+			}
+			byForm[form] = append(byForm[form], joinPath(append(path[:len(path):len(path)], key)))
+		}
+		for _, form := range order {
+			if len(byForm[form]) > 1 {
+				groups = append(groups, byForm[form])
+			}
+		}
+		return true
+	})
+	return groups
+}
@@ -0,0 +1,39 @@
+package pjson5
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNode_NonNormalizedKeys(t *testing.T) {
+	decomposed := "cafe\u0301" // e + 组合重音符
+	data := `{"café": 1, "` + decomposed + `": {"ok": 1, "A\u030a": 2}, list: [{"` + decomposed + `": 3}]}`
+	got := New(data).NonNormalizedKeys()
+	want := []string{decomposed, decomposed + ".A\u030a", "list.0." + decomposed}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("NonNormalizedKeys() = %q, want %q", got, want)
+	}
+	if got := New(rawJson).NonNormalizedKeys(); got != nil {
+		t.Fatalf("NonNormalizedKeys() = %q, want nil", got)
+	}
+}
+
+func TestNode_AmbiguousKeys(t *testing.T) {
+	data := `{
+  Port: 80,
+  "port": 8080,
+  host: "a",
+  " PORT ": 1,
+  nested: {Name: 1, name: 2, id: 3},
+  list: [{a: 1, A: 2}],
+}`
+	got := New(data).AmbiguousKeys()
+	want := [][]string{{"Port", "port", " PORT "}, {"nested.Name", "nested.name"}, {"list.0.a", "list.0.A"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("AmbiguousKeys() = %q, want %q", got, want)
+	}
+	if got := New(rawJson).AmbiguousKeys(); got != nil {
+		t.Fatalf("AmbiguousKeys() = %q, want nil", got)
+	}
+}
@@ -0,0 +1,103 @@
+package pjson5
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPathNotFound 路径对应的节点不存在
+var ErrPathNotFound = errors.New("path not found")
+
+// OptNode 可选值的访问链，由 Opt 创建。链上第一次出错（路径不存在、解析失败或类型不匹配）后，
+// 之后的Get不再查找，类型访问方法返回零值与该错误
+type OptNode struct {
+	node *Node
+	path []string // 从 Opt 开始的完整路径，用于错误信息
+	err  error
+}
+
+// Opt 返回以当前节点为起点的访问链，如 node.Opt().Get("a").Get("b").Int()，任一步的key不存在时返回 (0, err)
+func (n *Node) Opt() *OptNode {
+	return &OptNode{node: n, err: n.parse().Error()}
+}
+
+// Get 获取path对应的子节点，不存在时返回可通过 errors.Is(err, ErrPathNotFound) 识别的错误
+func (o *OptNode) Get(path string) *OptNode {
+	if o.err != nil {
+		return o
+	}
+	next := &OptNode{path: append(o.path[:len(o.path):len(o.path)], parsePath(path).PathNoe...)}
+	node, ok := o.node.Lookup(path)
+	switch {
+	case o.node.err != nil:
+		next.err = o.node.err
+	case !ok:
+		next.err = fmt.Errorf("%w: %s", ErrPathNotFound, patchPath(next.path))
+	}
+	next.node = node
+	return next
+}
+
+// Err 返回访问链上的第一个错误
+func (o *OptNode) Err() error {
+	return o.err
+}
+
+// Node 返回当前节点，出错时返回空节点与错误
+func (o *OptNode) Node() (*Node, error) {
+	if o.err != nil {
+		return &Node{}, o.err
+	}
+	return o.node, nil
+}
+
+// Int 返回整数值，非数字或带小数部分的数字返回错误
+func (o *OptNode) Int() (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
+	if o.node.typ != Number {
+		return 0, o.typeErr(Number)
+	}
+	if !o.node.IsIntegral() {
+		return 0, fmt.Errorf("value at %s is not an integer: %s", patchPath(o.path), o.node.val)
+	}
+	return o.node.Int()
+}
+
+// Float64 返回数字的值
+func (o *OptNode) Float64() (float64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
+	if o.node.typ != Number {
+		return 0, o.typeErr(Number)
+	}
+	return o.node.Float64()
+}
+
+// Bool 返回布尔值
+func (o *OptNode) Bool() (bool, error) {
+	if o.err != nil {
+		return false, o.err
+	}
+	if o.node.typ != Boolean {
+		return false, o.typeErr(Boolean)
+	}
+	return o.node.val == "true", nil
+}
+
+// Str 返回去掉引号并解码转义字符后的字符串
+func (o *OptNode) Str() (string, error) {
+	if o.err != nil {
+		return "", o.err
+	}
+	if o.node.typ != String {
+		return "", o.typeErr(String)
+	}
+	return unquote(o.node.val)
+}
+
+func (o *OptNode) typeErr(want Type) error {
+	return fmt.Errorf("value at %s is %s, not %s", patchPath(o.path), o.node.typ, want)
+}
@@ -0,0 +1,39 @@
+package pjson5
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNode_Opt(t *testing.T) {
+	node := New(rawJson)
+	if v, err := node.Opt().Get("map_key").Get("val").Int(); err != nil || v != 60000 {
+		t.Fatalf("Int() = %d, %v", v, err)
+	}
+	if v, err := node.Opt().Get("map_key.data_list").Get("0").Float64(); err != nil || v != 5000 {
+		t.Fatalf("Float64() = %v, %v", v, err)
+	}
+	if v, err := node.Opt().Get("map_key").Get("name").Str(); err != nil || v != "This is name" {
+		t.Fatalf("Str() = %q, %v", v, err)
+	}
+	// 中间的key不存在时短路，返回零值与包含完整路径的错误
+	v, err := node.Opt().Get("missing").Get("b").Get("c").Int()
+	if v != 0 || !errors.Is(err, ErrPathNotFound) || err.Error() != "path not found: missing" {
+		t.Fatalf("Int() = %d, %v", v, err)
+	}
+	if _, err := node.Opt().Get("map_key").Get("nope").Bool(); err == nil || err.Error() != "path not found: map_key.nope" {
+		t.Fatalf("Bool() error = %v", err)
+	}
+	if _, err := node.Opt().Get("string_key").Int(); err == nil || err.Error() != "value at string_key is String, not Number" {
+		t.Fatalf("Int() error = %v", err)
+	}
+	if _, err := New(`{a: 1.5}`).Opt().Get("a").Int(); err == nil {
+		t.Fatal("expected error for non-integral number")
+	}
+	if _, err := New(`{a: `).Opt().Get("a").Node(); err == nil {
+		t.Fatal("expected parse error")
+	}
+	if b, err := New(`{a: {b: true}}`).Opt().Get("a.b").Bool(); err != nil || !b {
+		t.Fatalf("Bool() = %v, %v", b, err)
+	}
+}
@@ -0,0 +1,136 @@
+package pjson5
+
+import "sync/atomic"
+
+// ParseOptions 控制解析行为，零值与 New 的默认行为一致
+type ParseOptions struct {
+	// LenientNull 接受任意大小写的 null 字面量，如 NULL、Null。默认只接受小写的 null
+	LenientNull bool
+	// AllowNumberUnderscore 允许数字中使用下划线作为分隔符，如 1_000_000，下划线必须位于两个数字之间
+	AllowNumberUnderscore bool
+	// CollectErrors 遇到可恢复的错误（非法的值、重复的key）时记录错误并继续解析，
+	// 通过 Errors 获取全部错误，Error 返回其中的第一个
+	CollectErrors bool
+	// AllowStringConcat 允许使用 + 拼接相邻的字符串，如 "part1" + "part2"，解析后保存为拼接后的单个字符串
+	AllowStringConcat bool
+	// AllowPartial 输入可能尚未结束：在对象、数组等结束前到达末尾时不报错，保留已完整解析的成员，
+	// 通过 NeedsMoreInput 判断是否需要更多输入，通过 Resume 追加输入后重新解析
+	AllowPartial bool
+	// AllowDuplicateKeys 允许对象中出现重复的key，后出现的值覆盖之前的值，被覆盖的key可通过 ShadowedKeys 获取。
+	// 被覆盖的成员不再输出，其注释保留在原位置
+	AllowDuplicateKeys bool
+	// AllowMissingValue 允许对象中的key之后缺少值，如 { "key": /* todo */ }，该key视为null值，注释保留在原位置，
+	// 格式化输出时补全为null
+	AllowMissingValue bool
+	// AllowCommentOnly 允许文档只包含注释而没有值，解析结果为保留注释的 None 类型节点，
+	// 对其 Set 时会在注释之后创建对象。不含注释的空文档仍然返回错误
+	AllowCommentOnly bool
+	// AllowShebang 允许文档的第一行为 #! 开头的shebang行，如 #!/usr/bin/env app，该行原样保留并在格式化时输出在最前面。
+	// 只识别位于文档第一个字节的 #!
+	AllowShebang bool
+	// MaxCommentLength 大于0时限制单个注释的最大字节数（包含 // 与 /* */，不含结尾的换行），
+	// 超过时返回 ErrCommentTooLong，用于防御超长注释或未闭合的块注释。0表示不限制
+	MaxCommentLength int
+	// MaxStringLength 大于0时限制单个字符串（包括带引号的key）引号之间的最大字节数，按转义前的原文计算，
+	// 超过时立即返回 ErrStringTooLong（未结束的字符串也是如此），位于值中时错误信息包含其完整路径。0表示不限制
+	MaxStringLength int
+	// ExactNumbers 解析数字时同时保存其精确值，Rat 直接返回该值，比较数字时使用精确值而不经过float64。
+	// Infinity、NaN 等无法精确表示的数字视为解析错误
+	ExactNumbers bool
+	// NumberValidator 非nil时替代内置的数字校验，参数为数字字面量的原文（已按 AllowNumberUnderscore 识别结束位置，
+	// 下划线不会被去除），返回非nil错误表示拒绝该数字，错误会被包装在 ParseError 中
+	NumberValidator func(literal string) error
+	// ValueHooks 解析时依次执行的值钩子，多个钩子匹配同一成员时按顺序处理，见 ValueHook。
+	// 钩子在节点解析时执行，每个节点只执行一次
+	ValueHooks []ValueHook
+}
+
+var defaultParseOptions = ParseOptions{}
+
+// NewWithOptions 使用指定的解析选项创建节点，选项对所有子节点生效
+func NewWithOptions(json string, opts ParseOptions) *Node {
+	return &Node{raw: json, opts: &opts}
+}
+
+func (n *Node) options() *ParseOptions {
+	if n.opts == nil {
+		return &defaultParseOptions
+	}
+	return n.opts
+}
+
+// Options 控制 Format 的输出格式，零值与 Pretty 的输出一致
+type Options struct {
+	// InlineObjectWidth 大于0时，不含注释且单行渲染后字符数小于该值的对象以单行形式输出，如 { "a": 1 }
+	InlineObjectWidth int
+	// PreserveColonSpacing 按原文保留冒号前后的空白，如 key:value、key : value，默认统一输出为 key: value
+	PreserveColonSpacing bool
+	// NoBracketPadding 单行的对象/数组在开始符之后、结束符之前不输出空格，如 {a: 1}，并按输出的实际位置缩进，
+	// 单行的对象成员之间不换行。默认保持 Pretty 的输出
+	NoBracketPadding bool
+	// TrimTrailingSpace 删除每一行末尾的空格与制表符，包括原样输出的值与注释中的行尾空白
+	TrimTrailingSpace bool
+	// EmptyCollections 空对象/空数组的输出形式，默认与 Pretty 一致
+	EmptyCollections EmptyStyle
+	// CommentColumn 大于0时，值、逗号或括号之后到行尾的注释对齐到该列：注释之前的内容不足CommentColumn个字符时
+	// 补齐空格，使注释从第CommentColumn+1个字符开始，超过时与内容间隔一个空格。字符数按Unicode字符计算
+	CommentColumn int
+	// ThousandsSeparator 十进制数字的整数部分每三位插入逗号，如 1,000,000，仅用于展示，输出无法再被解析
+	ThousandsSeparator bool
+
+	lineAware bool // Reformat 使用：按输出的实际位置决定缩进
+}
+
+var defaultOptions atomic.Pointer[Options]
+
+// SetDefaultOptions 设置 Pretty 的包级默认格式选项，对未通过 WithOptions 设置格式的节点生效。
+// 零值即原有的输出格式，可与 Pretty 并发调用
+func SetDefaultOptions(opts Options) {
+	defaultOptions.Store(&opts)
+}
+
+// WithOptions 设置当前节点 Pretty 使用的格式选项，覆盖包级默认选项。
+// 选项对已解析与之后解析出的子节点同样生效，包括通过 Get 获取的子节点；Format 始终使用传入的选项
+func (n *Node) WithOptions(opts Options) *Node {
+	n.setFormat(&opts)
+	return n
+}
+
+// setFormat 设置节点及已创建的子节点的格式选项
+func (n *Node) setFormat(opts *Options) {
+	n.format = opts
+	for _, child := range n.children {
+		child.setFormat(opts)
+	}
+}
+
+// formatOptions 返回 Pretty 使用的格式选项
+func (n *Node) formatOptions() Options {
+	if n.format != nil {
+		return *n.format
+	}
+	if opts := defaultOptions.Load(); opts != nil {
+		return *opts
+	}
+	return Options{}
+}
+
+// EmptyStyle 空对象/空数组的输出形式
+type EmptyStyle int
+
+const (
+	// EmptyDefault 不做处理：解析过的空集合按括号间距规则输出，未解析的按原文输出
+	EmptyDefault EmptyStyle = iota
+	// EmptyCompact 统一输出为 {} 与 []
+	EmptyCompact
+	// EmptySpaced 统一输出为 { } 与 [ ]
+	EmptySpaced
+)
+
+// render 按指定形式输出空集合，pair为集合的开始符与结束符
+func (s EmptyStyle) render(pair [2]byte) string {
+	if s == EmptySpaced {
+		return string(pair[0]) + " " + string(pair[1])
+	}
+	return string(pair[:])
+}
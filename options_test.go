@@ -0,0 +1,302 @@
+package pjson5
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseOptions_LenientNull(t *testing.T) {
+	tests := []struct {
+		literal     string
+		wantLenient bool
+		wantStrict  bool
+	}{
+		{literal: "null", wantLenient: true, wantStrict: true},
+		{literal: "NULL", wantLenient: true, wantStrict: false},
+		{literal: "Null", wantLenient: true, wantStrict: false},
+		{literal: "nULL", wantLenient: true, wantStrict: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				want := tt.wantLenient
+				if strict {
+					want = tt.wantStrict
+				}
+				opts := ParseOptions{LenientNull: !strict}
+				root := NewWithOptions(tt.literal, opts)
+				if ok := root.Parse().Error() == nil; ok != want {
+					t.Fatalf("strict=%v root %s: parsed=%v, want %v (err=%v)", strict, tt.literal, ok, want, root.Error())
+				}
+				if want && root.Type() != Null {
+					t.Fatalf("strict=%v root %s: expected Null type, got %v", strict, tt.literal, root.Type())
+				}
+				nested := NewWithOptions(`{"a": [`+tt.literal+`]}`, opts)
+				elem := nested.Get("a.0")
+				if ok := nested.Error() == nil && elem.Type() == Null; ok != want {
+					t.Fatalf("strict=%v nested %s: parsed=%v, want %v (err=%v)", strict, tt.literal, ok, want, nested.Error())
+				}
+			}
+		})
+	}
+	// 默认只接受小写的 null，NaN 仍按数字解析
+	if New("NULL").Parse().Error() == nil {
+		t.Fatal("expected NULL to be rejected by default")
+	}
+	if typ := NewWithOptions("NaN", ParseOptions{LenientNull: true}).Type(); typ != Number {
+		t.Fatalf("expected NaN to be Number, got %v", typ)
+	}
+}
+
+func TestParseOptions_AllowNumberUnderscore(t *testing.T) {
+	valid := []string{"1_000_000", "-1_000", "1_000.000_5", "1e1_0", "0xFF_FF", "0o7_7"}
+	invalid := []string{"1_", "1__000", "1_.5", "1._5", "1e_5", "0x_FF", "-_1"}
+	for _, s := range valid {
+		if New(s).Parse().Error() == nil {
+			t.Fatalf("expected %s to be rejected by default", s)
+		}
+		node := NewWithOptions(`{"n": `+s+`}`, ParseOptions{AllowNumberUnderscore: true})
+		n := node.Get("n")
+		if node.Error() != nil || n.Type() != Number {
+			t.Fatalf("expected %s to be a valid number, err=%v", s, node.Error())
+		}
+		if n.Value() != s {
+			t.Fatalf("expected raw literal %s to be kept, got %q", s, n.Value())
+		}
+	}
+	for _, s := range invalid {
+		node := NewWithOptions(`{"n": `+s+`}`, ParseOptions{AllowNumberUnderscore: true})
+		if node.Get("n").Type() == Number && node.Error() == nil {
+			t.Fatalf("expected %s to be rejected", s)
+		}
+	}
+}
+
+func TestParseOptions_AllowStringConcat(t *testing.T) {
+	input := `{"url": "https://" + 'example.com' +
+	  "/path", "list": ["a" + "b", "c"], "plain": "x"}`
+	for _, raw := range []string{input, `"a" + "b"`, `["a" + "b"]`} {
+		if New(raw).Parse().Error() == nil {
+			t.Fatalf("expected %s to be rejected by default", raw)
+		}
+	}
+
+	node := NewWithOptions(input, ParseOptions{AllowStringConcat: true})
+	if v := node.Get("url").Value(); v != `"https://example.com/path"` {
+		t.Fatalf("expected concatenated url, got %q (err=%v)", v, node.Error())
+	}
+	if v := node.Get("list.0").Value(); v != `"ab"` {
+		t.Fatalf("expected list[0]=\"ab\", got %q", v)
+	}
+	if v := node.Get("list.1").Value(); v != `"c"` {
+		t.Fatalf("expected list[1]=\"c\", got %q", v)
+	}
+	if v := node.Get("plain").Value(); v != `"x"` {
+		t.Fatalf("expected plain=\"x\", got %q", v)
+	}
+	if v := NewWithOptions(`"a" + "b"`, ParseOptions{AllowStringConcat: true}).Value(); v != `"a" + "b"` {
+		t.Fatalf("expected unparsed raw value, got %q", v)
+	}
+	if err := NewWithOptions(`"a" + 1`, ParseOptions{AllowStringConcat: true}).Parse().Error(); err == nil {
+		t.Fatal("expected error when concatenating a non-string")
+	}
+}
+
+func TestParseOptions_NumberValidator(t *testing.T) {
+	errScientific := errors.New("scientific notation not allowed")
+	opts := ParseOptions{NumberValidator: func(literal string) error {
+		if strings.ContainsAny(literal, "eE") && !strings.HasPrefix(literal, "0x") {
+			return errScientific
+		}
+		return nil
+	}}
+	for _, s := range []string{"1", "-2.5", "0xEE", "Infinity"} {
+		node := NewWithOptions(`{"n": `+s+`}`, opts)
+		if n := node.Get("n"); node.Error() != nil || n.Type() != Number || n.Value() != s {
+			t.Fatalf("expected %s to be accepted, got %q (err=%v)", s, n.Value(), node.Error())
+		}
+	}
+	node := NewWithOptions(`{"a": 1, "n": [1e5]}`, opts)
+	node.Get("n.0")
+	var perr *ParseError
+	if err := node.Error(); !errors.As(err, &perr) || !errors.Is(err, errScientific) {
+		t.Fatalf("expected ParseError wrapping the validator error, got %v", err)
+	}
+	if perr.Offset != strings.Index(`{"a": 1, "n": [1e5]}`, "1e5") {
+		t.Fatalf("expected error at the literal, got offset %d", perr.Offset)
+	}
+	if New("1e5").Parse().Error() != nil {
+		t.Fatal("expected the built-in validation without NumberValidator")
+	}
+}
+
+func TestParseOptions_AllowMissingValue(t *testing.T) {
+	inputs := []string{
+		`{ "key": /* todo */ }`,
+		`{ "key": /* todo */, "b": 1 }`,
+		"{\n  \"key\": // todo\n  ,\n  \"b\": 1\n}",
+		`{ "key": }`,
+	}
+	wantPretty := []string{
+		`{   "key": /* todo */null}`,
+		`{   "key": /* todo */null,
+  "b": 1}`,
+		"{\n  \"key\": // todo\n  null,\n  \"b\": 1\n}",
+		`{   "key": null}`,
+	}
+	for i, input := range inputs {
+		if err := New(input).Parse().Error(); err == nil || !strings.Contains(err.Error(), "missing value for key key") {
+			t.Fatalf("expected %q to be rejected by default, got %v", input, err)
+		}
+		node := NewWithOptions(input, ParseOptions{AllowMissingValue: true})
+		if typ := node.Get("key").Type(); node.Error() != nil || typ != Null {
+			t.Fatalf("expected key to be null in %q, got %v (err=%v)", input, typ, node.Error())
+		}
+		if got := node.Pretty(); got != wantPretty[i] {
+			t.Fatalf("Pretty(%q) = %q, want %q", input, got, wantPretty[i])
+		}
+	}
+	if err := NewWithOptions(`{"key"}`, ParseOptions{AllowMissingValue: true}).Parse().Error(); err == nil {
+		t.Fatal("expected key without colon to be rejected")
+	}
+	node := NewWithOptions(`{"a": , "b": 1}`, ParseOptions{CollectErrors: true})
+	if errs := node.Errors(); len(errs) != 1 || node.children["b"].Value() != "1" || node.children["a"] != nil {
+		t.Fatalf("expected the member to be skipped in CollectErrors mode, errs=%v", errs)
+	}
+}
+
+func TestParseOptions_MaxCommentLength(t *testing.T) {
+	opts := ParseOptions{MaxCommentLength: 10}
+	tests := []struct {
+		data    string
+		wantErr error
+	}{
+		{data: "{\"a\": 1 // 1234567\n}"},
+		{data: "{\"a\": 1 /* 12345*/}"},
+		{data: "{\"a\": 1} // 1234567"},
+		{data: "{\"a\": 1 // 12345678\n}", wantErr: ErrCommentTooLong},
+		{data: "{\"a\": 1 /* 123456*/}", wantErr: ErrCommentTooLong},
+		{data: "{\"a\": 1} // 12345678", wantErr: ErrCommentTooLong},
+		{data: "{\"a\": 1 /* " + strings.Repeat("x", 100), wantErr: ErrCommentTooLong},
+		{data: "{\"a\": 1 /* x", wantErr: ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		err := NewWithOptions(tt.data, opts).Parse().Error()
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Fatalf("Parse(%q) error = %v, want %v", tt.data, err, tt.wantErr)
+		}
+	}
+	var pe *ParseError
+	data := "{\n  \"a\": 1, /* " + strings.Repeat("x", 100) + " */\n}"
+	if err := NewWithOptions(data, opts).Parse().Error(); !errors.As(err, &pe) || pe.Offset != strings.Index(data, "/*") {
+		t.Fatalf("expected error at the comment start, got %v", err)
+	}
+	if err := New(data).Parse().Error(); err != nil {
+		t.Fatalf("expected unlimited comments by default, got %v", err)
+	}
+}
+
+func TestParseOptions_AllowCommentOnly(t *testing.T) {
+	opts := ParseOptions{AllowCommentOnly: true}
+	for _, data := range []string{"// just a comment\n", "/* header */\n// 说明"} {
+		if err := New(data).Parse().Error(); !errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatalf("expected %q to be rejected by default, got %v", data, err)
+		}
+		node := NewWithOptions(data, opts)
+		if node.Type() != None || node.Error() != nil {
+			t.Fatalf("expected None node for %q, got %v (err=%v)", data, node.Type(), node.Error())
+		}
+		if got := node.Pretty(); got != data {
+			t.Fatalf("Pretty() = %q, want %q", got, data)
+		}
+		node.Set("a", 1)
+		reparsed := New(node.Pretty())
+		if reparsed.Get("a").Value() != "1" || len(reparsed.TrailingComments()) != 0 || !strings.HasPrefix(node.Pretty(), data) {
+			t.Fatalf("Set() on comment-only document = %q (err=%v)", node.Pretty(), reparsed.Error())
+		}
+	}
+	for _, data := range []string{"", " \n\t"} {
+		if err := NewWithOptions(data, opts).Parse().Error(); !errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatalf("expected empty document %q to be rejected, got %v", data, err)
+		}
+	}
+}
+
+func TestParseOptions_MaxStringLength(t *testing.T) {
+	opts := ParseOptions{MaxStringLength: 5}
+	tests := []struct {
+		data     string
+		wantErr  error
+		wantPath string
+	}{
+		{data: `{"a": "12345"}`},
+		{data: `{"a": 'x\'yz'}`},
+		{data: `"123456"`, wantErr: ErrStringTooLong},
+		{data: `{"a": "123456"}`, wantErr: ErrStringTooLong, wantPath: "a"},
+		{data: `{"123456": 1}`, wantErr: ErrStringTooLong},
+		{data: `{"a": [1, {"b": "123456"}]}`, wantErr: ErrStringTooLong, wantPath: "a.1.b"},
+		{data: `["12345", '123456']`, wantErr: ErrStringTooLong, wantPath: "1"},
+		{data: `{"a": {"123456": 1}}`, wantErr: ErrStringTooLong, wantPath: "a"},
+		{data: `{"a": "12345` + strings.Repeat("x", 100), wantErr: ErrStringTooLong, wantPath: "a"}, // 未结束的字符串超长时即返回
+	}
+	for _, tt := range tests {
+		err := NewWithOptions(tt.data, opts).Parse().Error()
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Fatalf("Parse(%q) error = %v, want %v", tt.data, err, tt.wantErr)
+		}
+		if tt.wantPath != "" && !strings.HasSuffix(err.Error(), fmt.Sprintf(" at path %q", tt.wantPath)) {
+			t.Fatalf("Parse(%q) error = %v, want path %q", tt.data, err, tt.wantPath)
+		}
+	}
+	data := "{\n  \"name\": \"ok\",\n  \"blob\": \"" + strings.Repeat("x", 100) + "\",\n}"
+	var pe *ParseError
+	err := NewWithOptions(data, opts).Parse().Error()
+	if !errors.As(err, &pe) || pe.Line != 3 || !strings.Contains(pe.Msg, `at path "blob"`) {
+		t.Fatalf("expected error naming the key, got %v", err)
+	}
+	if err := New(data).Parse().Error(); err != nil {
+		t.Fatalf("expected unlimited strings by default, got %v", err)
+	}
+}
+
+func TestParseOptions_AllowShebang(t *testing.T) {
+	opts := ParseOptions{AllowShebang: true}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "object", data: "#!/usr/bin/env app --config\n{a: 1, b: [1, 2]}", want: "#!/usr/bin/env app --config\n{   a: 1,\n  b: [1, 2]}"},
+		{name: "crlf", data: "#!/bin/app\r\n// 说明\n{a: 1}", want: "#!/bin/app\r\n// 说明\n{   a: 1}"},
+		{name: "scalar", data: "#!/bin/app\n42", want: "#!/bin/app\n42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New(tt.data).Parse().Error(); err == nil {
+				t.Fatal("expected shebang to be rejected by default")
+			}
+			node := NewWithOptions(tt.data, opts)
+			if node.Parse().Error() != nil {
+				t.Fatal("parse error:", node.Error())
+			}
+			node.Get("a")
+			if got := node.Pretty(); got != tt.want {
+				t.Fatalf("Pretty() = %q, want %q", got, tt.want)
+			}
+			if blocks := node.Blocks(); blocks[0].Type != BlockShebang || !strings.HasPrefix(tt.data, blocks[0].Text) {
+				t.Fatalf("Blocks()[0] = %+v", blocks[0])
+			}
+		})
+	}
+	node := NewWithOptions("#!/bin/app\n{a: 1}", opts).Set("b", 2)
+	if got := node.Pretty(); got != "#!/bin/app\n{   a: 1,\n  \"b\": 2\n}" {
+		t.Fatalf("Set() = %q", got)
+	}
+	for _, data := range []string{" #!/bin/app\n{}", "\n#!/bin/app\n{}", "{}\n#!/bin/app"} {
+		if err := NewWithOptions(data, opts).Parse().Error(); err == nil {
+			t.Fatalf("expected shebang outside line 1 to be rejected: %q", data)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package pjson5
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Op 的操作类型
+const (
+	OpSet    = "set"    // 将Path设置为Value，Path不存在时创建
+	OpDelete = "delete" // 删除Path
+)
+
+// Op 描述一次修改操作，可序列化后传输并通过 ApplyPatch 重放
+type Op struct {
+	Op    string `json:"op"`              // 操作类型，OpSet 或 OpDelete
+	Path  string `json:"path"`            // 点分路径，根节点为 $
+	Value string `json:"value,omitempty"` // OpSet 时新值的JSON5原文，包含值内部的注释
+}
+
+// Patch 按结构（忽略格式与注释）比较from与to，返回将from修改为to所需的最少的set/delete操作：
+// 对象逐key比较，数组按下标比较，多余的元素从末尾开始删除，值相等的子树不产生操作。
+// 字符串比较解码后的内容，数字比较数值，如 'a' 与 "a"、0x10 与 16 视为相等。任一文档解析失败时返回nil
+func Patch(from, to *Node) []Op {
+	if !from.parseTree() || !to.parseTree() {
+		return nil
+	}
+	ops := make([]Op, 0)
+	diffNodes(from, to, nil, &ops)
+	return ops
+}
+
+// ApplyPatch 按顺序执行ops中的操作，遇到错误时停止，错误记录在节点上
+func (n *Node) ApplyPatch(ops []Op) *Node {
+	for _, op := range ops {
+		switch op.Op {
+		case OpSet:
+			n.SetString(op.Path, op.Value)
+		case OpDelete:
+			n.Delete(op.Path)
+		default:
+			n.err = fmt.Errorf("unsupported patch op %q at %s", op.Op, op.Path)
+		}
+		if n.err != nil {
+			return n
+		}
+	}
+	return n
+}
+
+// diffNodes 比较from与to，将差异追加到ops中
+func diffNodes(from, to *Node, path []string, ops *[]Op) {
+	childPath := func(key string) []string {
+		return append(path[:len(path):len(path)], key)
+	}
+	switch {
+	case from.typ == Object && to.typ == Object:
+		for _, key := range from.orderedKeys() {
+			if _, ok := to.children[key]; !ok {
+				*ops = append(*ops, Op{Op: OpDelete, Path: patchPath(childPath(key))})
+			}
+		}
+		for _, key := range to.orderedKeys() {
+			if child, ok := from.children[key]; ok {
+				diffNodes(child, to.children[key], childPath(key), ops)
+			} else {
+				*ops = append(*ops, setOp(childPath(key), to.children[key]))
+			}
+		}
+	case from.typ == Array && to.typ == Array:
+		fromElems, toElems := from.orderedChildren(), to.orderedChildren()
+		for i := 0; i < min(len(fromElems), len(toElems)); i++ {
+			diffNodes(fromElems[i], toElems[i], childPath(strconv.Itoa(i)), ops)
+		}
+		for i := len(fromElems) - 1; i >= len(toElems); i-- {
+			*ops = append(*ops, Op{Op: OpDelete, Path: patchPath(childPath(strconv.Itoa(i)))})
+		}
+		for i := len(fromElems); i < len(toElems); i++ {
+			*ops = append(*ops, setOp(childPath(strconv.Itoa(i)), toElems[i]))
+		}
+	case !scalarEqual(from, to):
+		*ops = append(*ops, setOp(path, to))
+	}
+}
+
+func setOp(path []string, node *Node) Op {
+	return Op{Op: OpSet, Path: patchPath(path), Value: node.Format(Options{})}
+}
+
+// patchPath 返回操作使用的路径，根节点为 $
+func patchPath(path []string) string {
+	if len(path) == 0 {
+		return Root
+	}
+	return joinPath(path)
+}
+
+// scalarEqual 按JSON语义比较两个标量节点，类型不同或其中一个是容器时返回false
+func scalarEqual(a, b *Node) bool {
+	if a.typ != b.typ || a.typ == Object || a.typ == Array {
+		return false
+	}
+	if a.typ == Number {
+		return numberEqual(a.val, b.val)
+	}
+	va, okA := a.scalarValue()
+	vb, okB := b.scalarValue()
+	return okA && okB && va == vb
+}
+
+// numberEqual 精确比较两个数字字面量的数值，Infinity、NaN 等无法精确表示的数字比较原文
+func numberEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := exactNumber(a)
+	rb, errB := exactNumber(b)
+	return errA == nil && errB == nil && ra.Cmp(rb) == 0
+}
@@ -0,0 +1,72 @@
+package pjson5
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []Op
+	}{
+		{name: "equal_ignoring_format", from: rawJson, to: `{number_key: 0x2, string_key: 'www.com', array_key: [1, 2, 3, 4.0],
+			map_key: {name: "This is name", val: 6e4, data_list: [5000]}}`, want: []Op{}},
+		{
+			name: "set_and_delete",
+			from: `{a: 1, b: {c: 'x', d: true}, e: null}`,
+			to:   `{a: 2, b: {c: 'x', f: [1]}, g: {h: 1}}`,
+			want: []Op{
+				{Op: OpDelete, Path: "e"},
+				{Op: OpSet, Path: "a", Value: "2"},
+				{Op: OpDelete, Path: "b.d"},
+				{Op: OpSet, Path: "b.f", Value: "[ 1]"},
+				{Op: OpSet, Path: "g", Value: "{   h: 1}"},
+			},
+		},
+		{
+			name: "array_shrink",
+			from: `{a: [1, 2, 3, 4]}`,
+			to:   `{a: [1, 5]}`,
+			want: []Op{{Op: OpSet, Path: "a.1", Value: "5"}, {Op: OpDelete, Path: "a.3"}, {Op: OpDelete, Path: "a.2"}},
+		},
+		{
+			name: "array_grow",
+			from: `{a: [{b: 1}]}`,
+			to:   `{a: [{b: 2}, 'x']}`,
+			want: []Op{{Op: OpSet, Path: "a.0.b", Value: "2"}, {Op: OpSet, Path: "a.1", Value: "'x'"}},
+		},
+		{name: "type_change", from: `{a: {b: 1}}`, to: `{a: [1]}`, want: []Op{{Op: OpSet, Path: "a", Value: "[ 1]"}}},
+		{name: "root_replaced", from: `[1]`, to: `{a: 1}`, want: []Op{{Op: OpSet, Path: Root, Value: "{   a: 1}"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Patch(New(tt.from), New(tt.to))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Patch() = %+v, want %+v", got, tt.want)
+			}
+			// 序列化传输后重放得到与目标相同的值
+			data, _ := json.Marshal(got)
+			var ops []Op
+			if err := json.Unmarshal(data, &ops); err != nil {
+				t.Fatal(err)
+			}
+			node := New(tt.from).ApplyPatch(ops)
+			if node.Error() != nil {
+				t.Fatal("ApplyPatch() error:", node.Error())
+			}
+			if len(Patch(node, New(tt.to))) != 0 {
+				t.Fatalf("ApplyPatch() = %s, want %s", node.Pretty(), tt.to)
+			}
+		})
+	}
+	if Patch(New(`{a: }`), New(`{}`)) != nil {
+		t.Fatal("expected nil ops for invalid document")
+	}
+	if err := New(`{}`).ApplyPatch([]Op{{Op: "move", Path: "a"}}).Error(); err == nil {
+		t.Fatal("expected error for unsupported op")
+	}
+}
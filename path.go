@@ -0,0 +1,32 @@
+package pjson5
+
+import "strings"
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// PathToPointer 将点分路径（如 $.a.b.0）转换为 RFC 6901 JSON Pointer（如 /a/b/0），
+// 路径中的 ~ 和 / 分别转义为 ~0 和 ~1，根路径对应空字符串
+func PathToPointer(path string) string {
+	buf := &strings.Builder{}
+	for _, nodePath := range parsePath(path).PathNoe {
+		buf.WriteByte('/')
+		buf.WriteString(pointerEscaper.Replace(nodePath))
+	}
+	return buf.String()
+}
+
+// PointerToPath 将 RFC 6901 JSON Pointer 转换为以 $ 开头的点分路径，空字符串对应根路径 $。
+// 点分路径使用 '.' 作为分隔符且不支持转义，因此包含 '.' 的key无法用点分路径表示
+func PointerToPath(ptr string) string {
+	if ptr == "" {
+		return Root
+	}
+	tokens := strings.Split(strings.TrimPrefix(ptr, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return Root + "." + strings.Join(tokens, ".")
+}
@@ -0,0 +1,45 @@
+package pjson5
+
+import "testing"
+
+func TestPathToPointer(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: ""},
+		{path: "$", want: ""},
+		{path: "$.a.b.0", want: "/a/b/0"},
+		{path: "map_key.data_list", want: "/map_key/data_list"},
+		{path: "$.a/b.c~d", want: "/a~1b/c~0d"},
+	}
+	for _, tt := range tests {
+		if got := PathToPointer(tt.path); got != tt.want {
+			t.Fatalf("PathToPointer(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPointerToPath(t *testing.T) {
+	tests := []struct {
+		ptr  string
+		want string
+	}{
+		{ptr: "", want: "$"},
+		{ptr: "/a/b/0", want: "$.a.b.0"},
+		{ptr: "/a~1b/c~0d", want: "$.a/b.c~d"},
+		{ptr: "/~01", want: "$.~1"},
+	}
+	for _, tt := range tests {
+		if got := PointerToPath(tt.ptr); got != tt.want {
+			t.Fatalf("PointerToPath(%q) = %q, want %q", tt.ptr, got, tt.want)
+		}
+	}
+	node := New(rawJson)
+	if v := node.Get(PointerToPath("/map_key/data_list/0")).Value(); v != "5000" {
+		t.Fatalf("expected map_key.data_list[0]=5000, got %q", v)
+	}
+	if got := PointerToPath(PathToPointer("$.map_key.val")); got != "$.map_key.val" {
+		t.Fatalf("expected round trip, got %q", got)
+	}
+}
@@ -0,0 +1,170 @@
+package pjson5
+
+// Reformat 忽略原文的换行与缩进，按结构重新排版输出：对象每个成员占一行，包含对象、数组或注释的数组每个元素占一行，
+// 只有标量的数组输出在一行内。注释仍跟随其所属的成员：成员之前的注释独占一行，值之后到行尾的注释保留在该成员的行尾，
+// 起始符号同行的注释保留在起始符号之后。原节点不会被修改，解析失败时返回错误信息
+func (n *Node) Reformat() string {
+	if n.parse().Error() != nil {
+		return n.err.Error()
+	}
+	c := n.clone()
+	c.parseTree()
+	c.walk(nil, func(_ []string, node *Node) bool {
+		if node.err == nil {
+			node.reformat()
+		}
+		return true
+	})
+	opts := n.formatOptions()
+	opts.TrimTrailingSpace = true
+	opts.lineAware = true
+	return c.Format(opts)
+}
+
+// reformatMember 重新排版时对象/数组的一个成员及其注释
+type reformatMember struct {
+	key      *dataBlock
+	val      dataBlock
+	leading  []dataBlock // 成员之前独占一行的注释
+	inner    []dataBlock // key与值之间的注释
+	trailing []dataBlock // 值之后到行尾的注释
+}
+
+// reformat 按结构重建对象/数组的block，丢弃原有的换行
+func (n *Node) reformat() {
+	if n.typ != Object && n.typ != Array {
+		return
+	}
+	var prefix, suffix, start, end, before, after, nextLeading []dataBlock
+	var members []*reformatMember
+	var cur *reformatMember
+	started, ended, inMember, seenBreak, trailingComma := false, false, false, false, false
+	// flush 分配上一个成员之后的注释：换行之前的属于上一个成员（起始符号之后则属于起始符号），换行之后的属于下一个成员，
+	// 没有换行时属于下一个成员
+	flush := func(next bool) {
+		switch {
+		case !seenBreak && next:
+			nextLeading = append(before, after...)
+		case cur == nil:
+			start, nextLeading = before, after
+		default:
+			cur.trailing = append(cur.trailing, before...)
+			nextLeading = after
+		}
+		if !next {
+			end, nextLeading = nextLeading, nil
+		}
+		before, after, seenBreak = nil, nil, false
+	}
+	for i, block := range n.block {
+		switch {
+		case !started:
+			if block.Typ == dataTypeStartFlag {
+				started = true
+				continue
+			}
+			prefix = append(prefix, block)
+		case ended:
+			suffix = append(suffix, block)
+		case block.Is(dataTypeComment | dataTypeCommentLine):
+			seenBreak = seenBreak || !inMember && n.ownLineComment(i) // 独占一行的注释之前必然有换行
+			switch {
+			case inMember:
+				cur.inner = append(cur.inner, block)
+			case seenBreak:
+				after = append(after, block)
+			default:
+				before = append(before, block)
+			}
+			seenBreak = seenBreak || !inMember && endsWithLineBreak(block)
+		case block.Typ == dataTypeLineBreak:
+			seenBreak = seenBreak || !inMember
+		case block.Typ == dataTypeKey:
+			flush(true)
+			key := block
+			cur = &reformatMember{key: &key, leading: nextLeading}
+			members, inMember, trailingComma = append(members, cur), true, false
+		case block.Typ == dataTypeVal:
+			if n.typ == Array {
+				flush(true)
+				cur = &reformatMember{leading: nextLeading}
+				members, trailingComma = append(members, cur), false
+			}
+			cur.val, inMember = block, false
+		case block.Typ == dataTypeComma:
+			trailingComma = cur != nil
+		case block.Typ == dataTypeEndFlag:
+			flush(false)
+			ended = true
+		}
+	}
+	blocks := append(prefix, dataBlock{Typ: dataTypeStartFlag})
+	if n.inlineReformat(members, start, end) {
+		blocks = append(blocks, start...)
+		for i, member := range members {
+			if i > 0 {
+				blocks = append(blocks, dataBlock{Typ: dataTypeComma})
+			}
+			blocks = append(blocks, member.val)
+		}
+		n.block = append(append(blocks, dataBlock{Typ: dataTypeEndFlag}), suffix...)
+		return
+	}
+	blocks = appendLine(blocks, start, dataTypeCommentLine)
+	for i, member := range members {
+		for _, comment := range member.leading {
+			blocks = appendLine(blocks, []dataBlock{comment}, dataTypeComment)
+		}
+		if member.key != nil {
+			blocks = append(blocks, *member.key, dataBlock{Typ: dataTypeColon})
+			blocks = append(blocks, member.inner...)
+		}
+		blocks = append(blocks, member.val)
+		if i < len(members)-1 || trailingComma {
+			blocks = append(blocks, dataBlock{Typ: dataTypeComma})
+		}
+		blocks = appendLine(blocks, member.trailing, dataTypeCommentLine)
+	}
+	for _, comment := range end {
+		blocks = appendLine(blocks, []dataBlock{comment}, dataTypeComment)
+	}
+	n.block = append(append(blocks, dataBlock{Typ: dataTypeEndFlag}), suffix...)
+}
+
+// inlineReformat 判断重新排版后是否输出在一行内：空集合，以及不含注释且元素均为标量的数组。
+// 空集合中只有块注释时同样输出在一行内
+func (n *Node) inlineReformat(members []*reformatMember, start, end []dataBlock) bool {
+	if len(end) > 0 {
+		return false
+	}
+	if len(members) == 0 { // 只有起始符号同行注释的空集合，如 { /* todo */ }
+		return len(start) == 0 || !endsWithLineBreak(start[len(start)-1])
+	}
+	if len(start) > 0 {
+		return false
+	}
+	if n.typ != Array {
+		return false
+	}
+	for _, member := range members {
+		if len(member.leading)+len(member.trailing) > 0 {
+			return false
+		}
+		if child := n.children[member.val.Val]; child.typ == Object || child.typ == Array {
+			return false
+		}
+	}
+	return true
+}
+
+// appendLine 以typ类型追加注释并结束当前行，注释自带换行时不再追加换行
+func appendLine(blocks, comments []dataBlock, typ int32) []dataBlock {
+	for _, comment := range comments {
+		comment.Typ = typ
+		blocks = append(blocks, comment)
+	}
+	if len(blocks) == 0 || !endsWithLineBreak(blocks[len(blocks)-1]) {
+		blocks = append(blocks, dataBlock{Typ: dataTypeLineBreak})
+	}
+	return blocks
+}
@@ -0,0 +1,48 @@
+package pjson5
+
+import "testing"
+
+func TestNode_Reformat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "collapsed",
+			data: "{a:1,b:{c:[1,2,3],d:[{e:1}]},  // tail b\n g: /* in */ 'x', /* lead h */ h: null}",
+			want: "{\n  a: 1,\n  b: {\n    c: [ 1, 2, 3 ],\n    d: [\n      {\n        e: 1\n      }\n    ]\n  }, // tail b\n  g: /* in */'x',\n  /* lead h */\n  h: null\n}",
+		},
+		{
+			name: "misindented",
+			data: "{ // 说明\n\t\t\"a\": 1,\n      // b的说明\n \"b\": [ 1,\n 2 ],\n\n\n}\n// 末尾",
+			want: "{ // 说明\n  \"a\": 1,\n  // b的说明\n  \"b\": [ 1, 2 ],\n}\n// 末尾",
+		},
+		{
+			name: "array_comments",
+			data: "[ // start\n1, 2 /* two */,\n// lead\n3]",
+			want: "[ // start\n  1,\n  2, /* two */\n  // lead\n  3\n]",
+		},
+		{
+			name: "empty",
+			data: "{a: {}, b: [  ], c: { /* todo */ }}",
+			want: "{\n  a: { },\n  b: [ ],\n  c: { /* todo */ }\n}",
+		},
+		{name: "scalar", data: "// c\n 1 ", want: "// c\n1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.data)
+			got := node.Reformat()
+			if got != tt.want {
+				t.Fatalf("Reformat() = %q, want %q", got, tt.want)
+			}
+			if again := New(got).Reformat(); again != got {
+				t.Fatalf("Reformat() is not stable: %q", again)
+			}
+			if node.Pretty() == got && tt.name != "scalar" {
+				t.Fatal("expected the original node to keep its layout")
+			}
+		})
+	}
+}
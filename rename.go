@@ -0,0 +1,94 @@
+package pjson5
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// RenameKeys 使用transform转换整棵树中所有对象的key，同时保留值与注释。
+// 同一对象中转换后出现重复的key时返回错误，此时文档不做任何修改
+func (n *Node) RenameKeys(transform func(key string) string) *Node {
+	if n.parse().Error() != nil {
+		return n
+	}
+	var objects []*Node
+	var renames []map[string]string
+	n.walk(nil, func(path []string, node *Node) bool {
+		if n.err != nil {
+			return false
+		}
+		if node.parse().Error() != nil {
+			n.err = node.err
+			return false
+		}
+		if node.typ != Object {
+			return true
+		}
+		rename := make(map[string]string, len(node.children))
+		owners := make(map[string]string, len(node.children))
+		for _, key := range node.orderedKeys() {
+			newKey := transform(key)
+			if owner, ok := owners[newKey]; ok {
+				n.err = fmt.Errorf("rename key collision at %s: %q and %q both become %q",
+					joinPath(path), owner, key, newKey)
+				return false
+			}
+			owners[newKey] = key
+			if newKey != key {
+				rename[key] = newKey
+			}
+		}
+		if len(rename) > 0 {
+			objects = append(objects, node)
+			renames = append(renames, rename)
+		}
+		return true
+	})
+	if n.err != nil {
+		return n
+	}
+	for i, node := range objects {
+		node.renameKeys(renames[i])
+	}
+	return n
+}
+
+// renameKeys 按rename重命名对象自身的key
+func (n *Node) renameKeys(rename map[string]string) {
+	children := make(map[string]*Node, len(n.children))
+	for key, child := range n.children {
+		if newKey, ok := rename[key]; ok {
+			key = newKey
+		}
+		children[key] = child
+	}
+	n.children = children
+	for i, block := range n.block {
+		if block.Typ != dataTypeKey {
+			continue
+		}
+		if newKey, ok := rename[block.KeyUnQuot()]; ok {
+			n.block[i] = renameKeyBlock(block, newKey)
+		}
+	}
+}
+
+// renameKeyBlock 返回重命名后的key block，原key不带引号且新key是合法标识符时保持不带引号
+func renameKeyBlock(block dataBlock, newKey string) dataBlock {
+	val := quoteString(newKey)
+	if c := block.Val[0]; c != '"' && c != '\'' && isIdentifier(newKey) {
+		val = newKey
+	}
+	return dataBlock{Typ: dataTypeKey, Val: val, Key: newKey}
+}
+
+// isIdentifier 判断s是否可以作为不带引号的key
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if r == '$' || r == '_' || unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r) {
+			continue
+		}
+		return false
+	}
+	return s != ""
+}
@@ -0,0 +1,53 @@
+package pjson5
+
+import (
+	"strings"
+	"testing"
+)
+
+func snakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func TestNode_RenameKeys(t *testing.T) {
+	node := New(rawJson).RenameKeys(snakeToCamel)
+	if node.Error() != nil {
+		t.Fatal("rename error:", node.Error())
+	}
+	for path, want := range map[string]string{
+		"numberKey":         "2",
+		"mapKey.name":       `"This is name"`,
+		"mapKey.dataList.0": "5000",
+		"arrayKey.3":        "4",
+	} {
+		if v := node.Get(path).Value(); v != want {
+			t.Fatalf("expected %s=%s, got %q", path, want, v)
+		}
+	}
+	pretty := node.Pretty()
+	if strings.Contains(pretty, "_key") || !strings.Contains(pretty, `"numberKey": 2, // 人数`) {
+		t.Fatalf("unexpected rename result:\n%s", pretty)
+	}
+
+	unquoted := New(`{first_name: 1, 'last_name': 2}`).RenameKeys(snakeToCamel)
+	if got := unquoted.Pretty(); got != "{   firstName: 1,\n  \"lastName\": 2}" {
+		t.Fatalf("unexpected quoting after rename: %s", got)
+	}
+}
+
+func TestNode_RenameKeys_Collision(t *testing.T) {
+	input := `{"ok_key": 1, "nested": {"a_b": 1, "aB": 2}}`
+	node := New(input).RenameKeys(snakeToCamel)
+	if node.Error() == nil || !strings.Contains(node.Error().Error(), `"aB"`) {
+		t.Fatalf("expected collision error, got %v", node.Error())
+	}
+	if _, ok := node.children["ok_key"]; !ok {
+		t.Fatal("expected document to be unchanged on collision")
+	}
+}
@@ -0,0 +1,62 @@
+package pjson5
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ReplaceValue 将整棵树中与old相等的标量值替换为new，返回替换的个数。
+// old按JSON语义比较：字符串比较解码后的内容，数字比较数值，如 'a' 与 "a"、1.0 与 1 视为相等
+func (n *Node) ReplaceValue(old, new any) int {
+	oldData, err := json.Marshal(old)
+	if err != nil {
+		n.err = fmt.Errorf("marshal data error:%w", err)
+		return 0
+	}
+	newData, err := json.Marshal(new)
+	if err != nil {
+		n.err = fmt.Errorf("marshal data error:%w", err)
+		return 0
+	}
+	var target any
+	if err = json.Unmarshal(oldData, &target); err != nil {
+		n.err = err
+		return 0
+	}
+	return n.rewriteValues(func(node *Node) (string, bool) {
+		v, ok := node.scalarValue()
+		return string(newData), ok && v == target
+	})
+}
+
+// rewriteValues 遍历整棵树中的标量值，fn返回true时使用其返回的原始文本替换该值，返回替换的个数
+func (n *Node) rewriteValues(fn func(node *Node) (string, bool)) int {
+	count := 0
+	n.walk(nil, func(_ []string, node *Node) bool {
+		if node.parse().Error() != nil || node.typ == Object || node.typ == Array {
+			return true
+		}
+		if raw, ok := fn(node); ok {
+			*node = Node{raw: raw, opts: node.opts, format: node.format, parent: node.parent}
+			count++
+		}
+		return false
+	})
+	return count
+}
+
+// scalarValue 将标量节点转换为与 json.Unmarshal 结果一致的Go值：nil、bool、float64或string
+func (n *Node) scalarValue() (any, bool) {
+	switch n.parse().typ {
+	case Null:
+		return nil, true
+	case Boolean:
+		return n.val == "true", true
+	case Number:
+		return parseNumberLiteral(n.val)
+	case String:
+		s, err := unquote(n.val)
+		return s, err == nil
+	}
+	return nil, false
+}
@@ -0,0 +1,51 @@
+package pjson5
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNode_ReplaceValue(t *testing.T) {
+	input := `{
+  "host": "old.example.com", // 主机
+  "backup": 'old.example.com',
+  "port": 8080,
+  "ports": [8080, 8080.0, 0x1F90, "8080"],
+  "nested": {"url": "old.example.com", "enabled": true},
+}`
+	tests := []struct {
+		name string
+		old  any
+		new  any
+		want int
+	}{
+		{name: "string", old: "old.example.com", new: "new.example.com", want: 3},
+		{name: "number", old: 8080, new: 9090, want: 4},
+		{name: "bool", old: true, new: false, want: 1},
+		{name: "no_match", old: "missing", new: "x", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(input)
+			if got := node.ReplaceValue(tt.old, tt.new); got != tt.want {
+				t.Fatalf("ReplaceValue() = %d, want %d\n%s", got, tt.want, node.Pretty())
+			}
+			if node.Error() != nil {
+				t.Fatal("replace error:", node.Error())
+			}
+		})
+	}
+
+	node := New(input)
+	node.ReplaceValue("old.example.com", "new.example.com")
+	pretty := node.Pretty()
+	if strings.Contains(pretty, "old.example.com") || !strings.Contains(pretty, `"host": "new.example.com", // 主机`) {
+		t.Fatalf("unexpected replace result:\n%s", pretty)
+	}
+	if v := New(pretty).Get("nested.url").Value(); v != `"new.example.com"` {
+		t.Fatalf("expected nested value to be replaced, got %q", v)
+	}
+	if node.ReplaceValue(make(chan int), 1); node.Error() == nil {
+		t.Fatal("expected marshal error")
+	}
+}
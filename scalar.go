@@ -0,0 +1,222 @@
+package pjson5
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ParseScalar 将s解析为单个标量值（字符串、数字、布尔或null），跳过对象、数组及注释的处理。
+// 除首尾空白外s必须只包含一个标量值，对象与数组会返回错误
+func ParseScalar(s string) (*Node, error) {
+	n := &Node{raw: s, parsed: true}
+	n.parseIdx, _ = skipWhiteSpace(s, 0)
+	if n.parseIdx >= len(s) {
+		n.eofErr(n.parseIdx)
+		return nil, n.err
+	}
+	startIdx := n.parseIdx
+	concatenated := false
+	switch n.typ = n.peekType(); n.typ {
+	case Object, Array:
+		return nil, n.newParseError(startIdx, fmt.Sprintf("expected scalar value at position %d, got %s", startIdx, string(s[startIdx])), nil)
+	case String:
+		concatenated = n.parseStringValue()
+	case Boolean:
+		n.parseBoolean()
+	case Null:
+		n.parseNull()
+	case Number:
+		n.parseNumber()
+	default:
+		n.parseErr(n.parseIdx)
+	}
+	if n.err != nil {
+		return nil, n.err
+	}
+	n.val = s[startIdx:n.parseIdx]
+	if concatenated {
+		joined, err := joinStringLiterals(n.val)
+		if err != nil {
+			n.parseErr(startIdx)
+			return nil, n.err
+		}
+		n.val = joined
+	}
+	if end, _ := skipWhiteSpace(s, n.parseIdx); end < len(s) {
+		n.parseErr(end)
+		return nil, n.err
+	}
+	n.block = []dataBlock{{Typ: dataTypeVal}}
+	return n, nil
+}
+
+// InRange 判断数字节点的值是否位于闭区间[lo, hi]内，非数字节点返回错误，NaN返回false
+func (n *Node) InRange(lo, hi float64) (bool, error) {
+	if n.parse().Error() != nil {
+		return false, n.err
+	}
+	if n.typ != Number {
+		return false, fmt.Errorf("value is not a number: %s", n.typ)
+	}
+	f, ok := parseNumberLiteral(n.val)
+	if !ok {
+		return false, fmt.Errorf("invalid number: %s", n.val)
+	}
+	if math.IsNaN(f) {
+		return false, nil
+	}
+	return f >= lo && f <= hi, nil
+}
+
+// IsIntegral 判断数字节点是否没有小数部分且可以用int64表示，如 2.0 和 1e3 为整数而 2.5 不是。
+// 直接检查数字字面量而不经过float64转换，超出int64范围的大整数不会因精度丢失被误判
+func (n *Node) IsIntegral() bool {
+	if n.parse().Error() != nil || n.typ != Number {
+		return false
+	}
+	s := strings.ReplaceAll(n.val, "_", "")
+	if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return true
+	}
+	digits := strings.TrimPrefix(s, "-")
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") ||
+		strings.HasPrefix(digits, "0o") || strings.HasPrefix(digits, "0O") {
+		return false
+	}
+	r, ok := new(big.Rat).SetString(s)
+	return ok && r.IsInt() && r.Num().IsInt64()
+}
+
+// Int 返回数字节点的整数值，支持 +1、十六进制 0x1F、八进制 0o17 等写法，以0开头的十进制数字如 010 按十进制解析。
+// 2.0、1e3 等没有小数部分的数字同样可以转换；非数字节点、带小数部分的数字（应使用 Float64）以及超出int64范围的数字返回错误
+func (n *Node) Int() (int64, error) {
+	if n.parse().Error() != nil {
+		return 0, n.err
+	}
+	if n.typ != Number {
+		return 0, fmt.Errorf("value is %s, not Number", n.typ)
+	}
+	s := trimLeadingZeros(strings.TrimPrefix(strings.ReplaceAll(n.val, "_", ""), "+"))
+	i, err := strconv.ParseInt(s, 0, 64)
+	if err == nil {
+		return i, nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if errors.Is(err, strconv.ErrRange) || ok && r.IsInt() && !r.Num().IsInt64() {
+		return 0, fmt.Errorf("integer out of int64 range: %s", n.val)
+	}
+	if !ok || !r.IsInt() {
+		return 0, fmt.Errorf("value is not an integer: %s", n.val)
+	}
+	return r.Num().Int64(), nil
+}
+
+// Float64 返回数字节点的值，支持十六进制、Infinity、NaN 等JSON5写法。-0、-0.0 返回带符号的负零，
+// 可通过 math.Signbit 与 0 区分。非数字节点返回错误
+func (n *Node) Float64() (float64, error) {
+	if n.parse().Error() != nil {
+		return 0, n.err
+	}
+	if n.typ != Number {
+		return 0, fmt.Errorf("value is %s, not Number", n.typ)
+	}
+	f, ok := parseNumberLiteral(n.val)
+	if !ok {
+		return 0, fmt.Errorf("invalid number: %s", n.val)
+	}
+	return f, nil
+}
+
+// Rat 返回数字节点的精确值，不经过float64转换，如 0.1 返回 1/10。开启 ExactNumbers 时返回解析时保存的值的拷贝。
+// Infinity、NaN 以及非数字节点返回错误
+func (n *Node) Rat() (*big.Rat, error) {
+	if n.parse().Error() != nil {
+		return nil, n.err
+	}
+	if n.typ != Number {
+		return nil, fmt.Errorf("value is %s, not Number", n.typ)
+	}
+	if n.rat != nil {
+		return new(big.Rat).Set(n.rat), nil
+	}
+	return exactNumber(n.val)
+}
+
+// exactNumber 将数字字面量转换为精确值，支持十六进制、八进制、下划线分隔等写法
+func exactNumber(literal string) (*big.Rat, error) {
+	s := strings.TrimPrefix(strings.ReplaceAll(literal, "_", ""), "+")
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("number %s cannot be represented exactly", literal)
+	}
+	return r, nil
+}
+
+// Float 与 Float64 相同，Infinity、-Infinity 返回正负无穷，NaN 返回 math.NaN()，与 Int 对应
+func (n *Node) Float() (float64, error) {
+	return n.Float64()
+}
+
+// Bool 返回布尔节点的值，只接受 true 与 false 字面量，1、0、"true" 等其他类型的值返回错误
+func (n *Node) Bool() (bool, error) {
+	if n.parse().Error() != nil {
+		return false, n.err
+	}
+	if n.typ != Boolean {
+		return false, fmt.Errorf("value is %s, not Boolean", n.typ)
+	}
+	return n.val == "true", nil
+}
+
+// Str 返回字符串节点去掉引号（单引号或双引号）并解码转义字符后的内容，支持 \n、\uXXXX 等转义，
+// 反斜杠之后紧跟换行的续行写法不产生字符。非字符串节点返回错误
+func (n *Node) Str() (string, error) {
+	if n.parse().Error() != nil {
+		return "", n.err
+	}
+	if n.typ != String {
+		return "", fmt.Errorf("value is %s, not String", n.typ)
+	}
+	return unquote(n.val)
+}
+
+// StringSlice 将字符串或字符串数组转换为[]string，用于允许写成单个值或数组的配置，如 hosts: "a" 与 hosts: ["a", "b"]。
+// 字符串返回只含一个元素的切片，字符串会去掉引号并解码转义字符；其他类型或包含非字符串元素的数组返回错误
+func (n *Node) StringSlice() ([]string, error) {
+	if n.parse().Error() != nil {
+		return nil, n.err
+	}
+	switch n.typ {
+	case String:
+		s, err := unquote(n.val)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	case Array:
+		elems := n.orderedChildren()
+		result := make([]string, 0, len(elems))
+		for i, elem := range elems {
+			if elem.parse().Error() != nil {
+				return nil, elem.err
+			}
+			if elem.typ != String {
+				return nil, fmt.Errorf("array element %d is %s, not String", i, elem.typ)
+			}
+			s, err := unquote(elem.val)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("value is %s, not String or Array", n.typ)
+}
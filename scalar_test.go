@@ -0,0 +1,343 @@
+package pjson5
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseScalar(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		typ     Type
+		val     string
+		wantErr bool
+	}{
+		{name: "number", input: " 42 ", typ: Number, val: "42"},
+		{name: "hex", input: "0x1F", typ: Number, val: "0x1F"},
+		{name: "string", input: `'single'`, typ: String, val: `'single'`},
+		{name: "bool", input: "true", typ: Boolean, val: "true"},
+		{name: "null", input: "null", typ: Null, val: "null"},
+		{name: "infinity", input: "-Infinity", typ: Number, val: "-Infinity"},
+		{name: "object", input: `{"a": 1}`, wantErr: true},
+		{name: "array", input: "[1]", wantErr: true},
+		{name: "trailing", input: "1 2", wantErr: true},
+		{name: "comment", input: "1 // one", wantErr: true},
+		{name: "invalid", input: "tru", wantErr: true},
+		{name: "empty", input: "  ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseScalar(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScalar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if node.Type() != tt.typ || node.Value() != tt.val {
+				t.Fatalf("ParseScalar() = (%v, %q), want (%v, %q)", node.Type(), node.Value(), tt.typ, tt.val)
+			}
+			if node.Pretty() != tt.val {
+				t.Fatalf("unexpected Pretty(): %q", node.Pretty())
+			}
+		})
+	}
+	if _, err := ParseScalar(`"abc`); !errors.Is(err, ErrUnexpectedEOF) {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestNode_InRange(t *testing.T) {
+	node := New(`{"port": 8080, "neg": -1.5, "hex": 0xFF, "inf": Infinity, "nan": NaN, "name": "x"}`)
+	tests := []struct {
+		path     string
+		min, max float64
+		want     bool
+		wantErr  bool
+	}{
+		{path: "port", min: 1, max: 65535, want: true},
+		{path: "port", min: 1, max: 1024, want: false},
+		{path: "neg", min: -1.5, max: 0, want: true},
+		{path: "hex", min: 255, max: 255, want: true},
+		{path: "inf", min: 0, max: 1e300, want: false},
+		{path: "nan", min: -1, max: 1, want: false},
+		{path: "name", min: 0, max: 1, wantErr: true},
+		{path: "missing", min: 0, max: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := node.Get(tt.path).InRange(tt.min, tt.max)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: InRange() error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: InRange(%v, %v) = %v, want %v", tt.path, tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestNode_IsIntegral(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{data: "2", want: true},
+		{data: "-2.0", want: true},
+		{data: "+1e3", want: true},
+		{data: "1.5e1", want: true},
+		{data: "5.", want: true},
+		{data: "0xFF", want: true},
+		{data: "0o17", want: true},
+		{data: "2.5", want: false},
+		{data: ".5", want: false},
+		{data: "1e-3", want: false},
+		{data: "9223372036854775807", want: true},
+		{data: "9223372036854775808", want: false},
+		{data: "-9223372036854775808", want: true},
+		{data: "1e19", want: false},
+		{data: "Infinity", want: false},
+		{data: "NaN", want: false},
+		{data: `"2"`, want: false},
+	}
+	for _, tt := range tests {
+		if got := New(tt.data).IsIntegral(); got != tt.want {
+			t.Fatalf("IsIntegral(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestNode_Int(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    int64
+		wantErr string
+	}{
+		{data: "42", want: 42},
+		{data: "+7", want: 7},
+		{data: "-12", want: -12},
+		{data: "0x1F", want: 31},
+		{data: "0o17", want: 15},
+		{data: "010", want: 10},
+		{data: "00", want: 0},
+		{data: "-00", want: 0},
+		{data: "00e1", want: 0},
+		{data: "007.0", want: 7},
+		{data: "2.0", want: 2},
+		{data: "1e3", want: 1000},
+		{data: "9223372036854775807", want: math.MaxInt64},
+		{data: "3.16", wantErr: "value is not an integer: 3.16"},
+		{data: "NaN", wantErr: "value is not an integer: NaN"},
+		{data: "9223372036854775808", wantErr: "integer out of int64 range: 9223372036854775808"},
+		{data: "1e30", wantErr: "integer out of int64 range: 1e30"},
+		{data: `"1"`, wantErr: "value is String, not Number"},
+		{data: "true", wantErr: "value is Boolean, not Number"},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Int()
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Int(%s) error = %v, want %q", tt.data, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("Int(%s) = %v, %v, want %v", tt.data, got, err, tt.want)
+		}
+	}
+	if got, err := NewWithOptions("1_000", ParseOptions{AllowNumberUnderscore: true}).Int(); err != nil || got != 1000 {
+		t.Fatalf("Int(1_000) = %v, %v", got, err)
+	}
+}
+
+func TestNode_Rat(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{data: "0.1", want: "1/10"},
+		{data: "+1.25e2", want: "125/1"},
+		{data: "-.5", want: "-1/2"},
+		{data: "0x1F", want: "31/1"},
+		{data: "12345678901234567890.000000000000000001", want: "12345678901234567890000000000000000001/1000000000000000000"},
+		{data: "Infinity", wantErr: true},
+		{data: "NaN", wantErr: true},
+		{data: `"1"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Rat()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Rat(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+		}
+		if err == nil && got.String() != tt.want {
+			t.Fatalf("Rat(%s) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+
+	opts := ParseOptions{ExactNumbers: true, AllowNumberUnderscore: true}
+	node := NewWithOptions(`{price: 0.1, qty: 1_000, list: [0.2]}`, opts)
+	price, err := node.Get("price").Rat()
+	if err != nil {
+		t.Fatal("Rat() error:", err)
+	}
+	price.Add(price, big.NewRat(2, 10)) // 修改返回值不影响节点
+	if got, _ := node.Get("price").Rat(); got.String() != "1/10" || price.String() != "3/10" {
+		t.Fatalf("Rat() = %s, sum = %s", got, price)
+	}
+	if got, _ := node.Get("qty").Rat(); got.String() != "1000/1" {
+		t.Fatalf("Rat(qty) = %s", got)
+	}
+	if got, _ := node.Get("list.0").Rat(); got.String() != "1/5" {
+		t.Fatalf("Rat(list.0) = %s", got)
+	}
+	err = NewWithOptions(`[1, NaN]`, opts).Parse().Error()
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Offset != 4 {
+		t.Fatalf("expected parse error for NaN with ExactNumbers, got %v", err)
+	}
+}
+
+func TestNode_Float64(t *testing.T) {
+	tests := []struct {
+		data     string
+		want     float64
+		negative bool
+		wantErr  bool
+	}{
+		{data: "-0", want: 0, negative: true},
+		{data: "-0.0", want: 0, negative: true},
+		{data: "0", want: 0},
+		{data: "+0.0", want: 0},
+		{data: "0x1F", want: 31},
+		{data: "-.5e1", want: -5, negative: true},
+		{data: "-Infinity", want: math.Inf(-1), negative: true},
+		{data: `"1"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Float64()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Float64(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+		}
+		if err == nil && (got != tt.want || math.Signbit(got) != tt.negative) {
+			t.Fatalf("Float64(%s) = %v (signbit %v), want %v (signbit %v)", tt.data, got, math.Signbit(got), tt.want, tt.negative)
+		}
+	}
+	for _, data := range []string{"Infinity", "-Infinity", "NaN"} {
+		f, err := New(data).Float()
+		if err != nil {
+			t.Fatalf("Float(%s) error: %v", data, err)
+		}
+		if want, _ := strconv.ParseFloat(data, 64); f != want && !(math.IsNaN(f) && math.IsNaN(want)) {
+			t.Fatalf("Float(%s) = %v, want %v", data, f, want)
+		}
+	}
+	for data, want := range map[string]string{`"1"`: "String", "true": "Boolean", "{a: 1}": "Object", "null": "Null"} {
+		if _, err := New(data).Float(); err == nil || err.Error() != "value is "+want+", not Number" {
+			t.Fatalf("Float(%s) error = %v, want type error for %s", data, err, want)
+		}
+	}
+	// -0 在解析、格式化与修改其他key后保持原文
+	node := New(`{a: -0, b: -0.0, c: 1}`).Set("c", 2)
+	if pretty := node.Pretty(); pretty != "{   a: -0,\n  b: -0.0,\n  c: 2}" {
+		t.Fatalf("Pretty() = %s", pretty)
+	}
+	if f, _ := New(node.Pretty()).Get("b").Float64(); !math.Signbit(f) {
+		t.Fatal("expected negative zero after round trip")
+	}
+	node = New(`{a: 1}`).Set("a", math.Copysign(0, -1)).SetFloat("b", math.Copysign(0, -1), 'f', 1)
+	if a, b := node.Get("a").Value(), node.Get("b").Value(); a != "-0" || b != "-0.0" {
+		t.Fatalf("Set negative zero = %s, %s", a, b)
+	}
+	if data, _ := node.MarshalJSON(); string(data) != `{"a":-0,"b":-0.0}` {
+		t.Fatalf("MarshalJSON() = %s", data)
+	}
+}
+
+func TestNode_Bool(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    bool
+		wantErr string
+	}{
+		{data: "true", want: true},
+		{data: " false // off", want: false},
+		{data: "1", wantErr: "value is Number, not Boolean"},
+		{data: `"true"`, wantErr: "value is String, not Boolean"},
+		{data: "null", wantErr: "value is Null, not Boolean"},
+		{data: "True", wantErr: "invalid JSON5 value at position 0: "},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Bool()
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Bool(%s) error = %v, want %q", tt.data, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("Bool(%s) = %v, %v, want %v", tt.data, got, err, tt.want)
+		}
+	}
+}
+
+func TestNode_Str(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		wantErr string
+	}{
+		{data: `"a\tb\n"`, want: "a\tb\n"},
+		{data: `'it\'s "x"'`, want: `it's "x"`},
+		{data: `"\u4e2d\u6587 \\ \/"`, want: "\u4e2d\u6587 \\ /"},
+		{data: `"\ud83d\ude00"`, want: "\U0001f600"},
+		{data: "'line \\\none'", want: "line one"},
+		{data: "\"crlf \\\r\nend\"", want: "crlf end"},
+		{data: `"\x41"`, want: "A"},
+		{data: "42", wantErr: "value is Number, not String"},
+		{data: "[]", wantErr: "value is Array, not String"},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Str()
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Str(%s) error = %v, want %q", tt.data, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("Str(%s) = %q, %v, want %q", tt.data, got, err, tt.want)
+		}
+	}
+}
+
+func TestNode_StringSlice(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{data: `"a"`, want: []string{"a"}},
+		{data: `'it\'s'`, want: []string{"it's"}},
+		{data: `["a", 'b', /* c */ "c"]`, want: []string{"a", "b", "c"}},
+		{data: `[]`, want: []string{}},
+		{data: `["a", 1]`, wantErr: true},
+		{data: `1`, wantErr: true},
+		{data: `{"a": "b"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).StringSlice()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("StringSlice(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("StringSlice(%s) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+	if hosts, err := New(rawJson).Get("map_key.name").StringSlice(); err != nil || len(hosts) != 1 {
+		t.Fatalf("StringSlice() = %q, %v", hosts, err)
+	}
+}
@@ -0,0 +1,314 @@
+package pjson5
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// scanner 不构建节点树的语法检查器，复用解析时的词法与校验规则，扫描结果直接丢弃。
+// 与解析时相同，对象与数组先按括号定位结束位置，待所在的一层检查完成后，再将这一段作为独立的文档检查
+type scanner struct {
+	*Node                  // 扫描位置与错误，只使用raw、offset、parseIdx、err等字段，不记录block
+	spans   []bracketSpan  // 预扫描得到的每个括号在输入中的位置，按开始位置排列
+	pending []bracketSpan  // 已定位但尚未检查的对象与数组，位置相对于当前的raw
+	keys    map[string]int // 检查重复的key，值为key所在对象的序号，各对象复用同一个集合而不必清空
+	objects int            // 已检查的对象个数
+	target  int            // 大于等于0时，记录包含该位置的各层成员到path中，用于确定错误所在的路径
+	path    []string
+}
+
+// bracketSpan 一对括号的位置，end为结束符之后的位置，未闭合时为-1。
+// spans中为在输入中的位置，pending中为相对于当前raw的位置
+type bracketSpan struct {
+	start, end int
+}
+
+// newScanner 创建从n.raw开头扫描的scanner，n只用于提供输入、位置与选项
+func newScanner(n *Node) *scanner {
+	s := &scanner{Node: n, target: -1}
+	s.matchBrackets()
+	return s
+}
+
+// matchBrackets 按 parseCombineEnd 的规则一次性扫描全部括号，记录每个 { 与 [ 对应的结束位置，
+// 使各层定位结束位置时不必重复扫描。遇到非法的字符串或注释时停止，之后的括号在使用时再单独定位
+func (s *scanner) matchBrackets() {
+	var objects, arrays []int // 尚未闭合的括号在spans中的下标
+	closeSpan := func(open *[]int) {
+		if len(*open) > 0 {
+			s.spans[(*open)[len(*open)-1]].end = s.offset + s.parseIdx + 1
+			*open = (*open)[:len(*open)-1]
+		}
+	}
+	for s.parseIdx < len(s.raw) && s.err == nil {
+		switch s.raw[s.parseIdx] {
+		case '"', '\'':
+			s.parseString()
+			continue
+		case backslash:
+			s.parseComment(false, false)
+			continue
+		case objectPair[0]:
+			objects = append(objects, len(s.spans))
+			s.spans = append(s.spans, bracketSpan{start: s.offset + s.parseIdx, end: -1})
+		case arrayPair[0]:
+			arrays = append(arrays, len(s.spans))
+			s.spans = append(s.spans, bracketSpan{start: s.offset + s.parseIdx, end: -1})
+		case objectPair[1]:
+			closeSpan(&objects)
+		case arrayPair[1]:
+			closeSpan(&arrays)
+		}
+		s.parseIdx++
+	}
+	s.parseIdx, s.err = 0, nil
+}
+
+// containerEnd 将parseIdx移动到当前位置的对象/数组的结束位置，预扫描的结果不可用时重新定位
+func (s *scanner) containerEnd(pair [2]byte) {
+	pos := s.offset + s.parseIdx
+	i := sort.Search(len(s.spans), func(i int) bool { return s.spans[i].start >= pos })
+	if i < len(s.spans) && s.spans[i].start == pos {
+		if end := s.spans[i].end - s.offset; end > 0 && end <= len(s.raw) {
+			s.parseIdx = end
+			return
+		}
+	}
+	s.parseCombineEnd(pair)
+}
+
+// element 检查当前位置的值，对象与数组只定位结束位置并记录到pending中
+func (s *scanner) element() {
+	pair := objectPair
+	switch s.peekType() {
+	case Object:
+	case Array:
+		pair = arrayPair
+	default:
+		s.parseObjectVal()
+		return
+	}
+	start := s.parseIdx
+	if s.containerEnd(pair); s.err == nil {
+		s.pending = append(s.pending, bracketSpan{start: start, end: s.parseIdx})
+	}
+}
+
+// locate 开启target时，若从start开始的成员包含target，将其记录到path中。
+// 各层按从外到内的顺序检查，path即为target所在的成员路径
+func (s *scanner) locate(member string, start int) {
+	if s.target >= 0 && s.offset+start <= s.target && s.target < s.offset+s.parseIdx {
+		s.path = append(s.path, member)
+	}
+}
+
+// value 检查当前位置的一个完整的值，包括对象与数组中的全部成员
+func (s *scanner) value() {
+	base := len(s.pending)
+	s.element()
+	s.descend(base)
+}
+
+// descend 依次检查pending[base:]中的对象与数组，完成后将其移出pending
+func (s *scanner) descend(base int) {
+	for i := base; i < len(s.pending) && s.err == nil; i++ {
+		s.region(s.pending[i])
+	}
+	s.pending = s.pending[:base]
+}
+
+// region 将raw中的一段作为独立的文档检查，与解析时子节点只包含自身的raw相同
+func (s *scanner) region(span bracketSpan) {
+	raw, offset, idx := s.raw, s.offset, s.parseIdx
+	if s.src == "" { // 错误的行列号按完整的输入计算
+		s.src = raw
+		defer func() { s.src = "" }()
+	}
+	s.raw, s.offset, s.parseIdx = raw[span.start:span.end], offset+span.start, 0
+	s.document()
+	s.raw, s.offset, s.parseIdx = raw, offset, idx
+}
+
+// document 按 parseValue 的规则检查一个完整的文档：值之前与之后只允许空白与注释，值之后允许一个逗号
+func (s *scanner) document() {
+	if s.options().AllowShebang && s.offset == 0 && strings.HasPrefix(s.raw, "#!") {
+		if end, lbLen := indexLineBreak(s.raw, 0); end >= 0 {
+			s.parseIdx = end + lbLen
+		} else {
+			s.parseIdx = len(s.raw)
+		}
+	}
+	start := s.parseIdx
+	for s.err == nil { // 与 parseValue 一致，不属于注释的 / 直接跳过
+		s.parseIdx, _ = skipWhiteSpace(s.raw, s.parseIdx)
+		if !s.except(backslash) {
+			break
+		}
+		s.parseComment(false, false)
+	}
+	if s.err != nil {
+		return
+	}
+	if s.parseIdx >= len(s.raw) {
+		if s.parseIdx == start || !s.options().AllowCommentOnly {
+			s.eofErr(s.parseIdx)
+		}
+		return
+	}
+	base := len(s.pending)
+	switch s.peekType() {
+	case Object:
+		s.object()
+	case Array:
+		s.array()
+	case None:
+		s.parseErr(s.parseIdx)
+	default:
+		s.parseObjectVal()
+	}
+	if s.err != nil {
+		return
+	}
+	s.parseIdx = skipLineWhiteSpace(s.raw, s.parseIdx)
+	if s.except(comma) {
+		s.parseIdx++
+	}
+	s.parseIdx, _ = skipWhiteSpace(s.raw, s.parseIdx)
+	for s.err == nil && s.parseIdx < len(s.raw) {
+		s.parseIdx, _ = skipWhiteSpace(s.raw, s.parseIdx)
+		if !s.except(backslash) {
+			s.parseErr(s.parseIdx)
+			break
+		}
+		s.parseComment(false, false)
+	}
+	s.descend(base)
+}
+
+// space 跳过空白与注释，出现不属于注释的 / 时记录错误
+func (s *scanner) space() {
+	for s.err == nil {
+		s.parseIdx, _ = skipWhiteSpace(s.raw, s.parseIdx)
+		if !s.except(backslash) {
+			return
+		}
+		pos := s.parseIdx
+		if _, suc := s.parseComment(false, false); s.err == nil && !suc {
+			s.parseErr(pos)
+		}
+	}
+}
+
+// object 按 parseObject 的规则检查对象的一层成员
+func (s *scanner) object() {
+	if s.keys == nil {
+		s.keys = make(map[string]int)
+	}
+	s.objects++
+	s.parseIdx++
+	key, hasKey, hasColon := "", false, false
+	for s.parseIdx < len(s.raw) && s.err == nil {
+		s.parseIdx, _ = skipWhiteSpace(s.raw, s.parseIdx)
+		if s.parseIdx >= len(s.raw) {
+			break
+		}
+		if c := s.raw[s.parseIdx]; (c == '}' || c == comma) && hasKey {
+			if !hasColon || !s.options().AllowMissingValue {
+				s.err = s.newParseError(s.parseIdx, fmt.Sprintf("missing value for key %s at position %d", key, s.offset+s.parseIdx), nil)
+				return
+			}
+			hasKey = false
+		}
+		switch s.raw[s.parseIdx] {
+		case '}':
+			s.parseIdx++
+			return
+		case backslash:
+			s.parseComment(false, false)
+			continue
+		case colon:
+			hasColon = hasColon || hasKey
+			s.parseIdx++
+			continue
+		case comma:
+			s.parseIdx++
+			continue
+		}
+		startIdx := s.parseIdx
+		if hasKey {
+			s.element()
+			if s.Node.stringTooLong(key, startIdx); s.err != nil {
+				return
+			}
+			s.locate(key, startIdx)
+			hasKey = false
+			s.parseIdx = skipLineWhiteSpace(s.raw, s.parseIdx)
+			if s.except(comma) {
+				s.parseIdx++
+			}
+			continue
+		}
+		if s.parseObjectKey(); s.err != nil {
+			return
+		}
+		var err error
+		if key, err = decodeKey(s.raw[startIdx:s.parseIdx]); err != nil {
+			s.parseErr(startIdx)
+			return
+		}
+		if !s.options().AllowDuplicateKeys {
+			if s.keys[key] == s.objects {
+				s.err = s.newParseError(startIdx, "repeat key:"+key, nil)
+				return
+			}
+			s.keys[key] = s.objects
+		}
+		hasKey, hasColon = true, false
+	}
+	if s.err == nil { // 未找到结束符
+		s.eofErr(s.parseIdx)
+	}
+}
+
+// array 按 parseArray 的规则检查数组的一层元素
+func (s *scanner) array() {
+	s.parseIdx++
+	afterVal := false // 上一个元素之后是否还没有逗号
+	elemIdx := 0
+	for s.parseIdx < len(s.raw) && s.err == nil {
+		s.parseIdx, _ = skipWhiteSpace(s.raw, s.parseIdx)
+		if s.parseIdx >= len(s.raw) {
+			break
+		}
+		switch s.raw[s.parseIdx] {
+		case ']':
+			s.parseIdx++
+			return
+		case backslash:
+			s.parseComment(false, false)
+			continue
+		case comma: // 元素与逗号之间有注释，如 [1 /* c */, 2]
+			if afterVal {
+				s.parseIdx++
+				afterVal = false
+				continue
+			}
+		}
+		startIdx := s.parseIdx
+		s.element()
+		if s.Node.stringTooLong(strconv.Itoa(elemIdx), startIdx); s.err != nil {
+			return
+		}
+		s.locate(strconv.Itoa(elemIdx), startIdx)
+		elemIdx++
+		s.parseIdx = skipLineWhiteSpace(s.raw, s.parseIdx)
+		if afterVal = !s.except(comma); !afterVal {
+			s.parseIdx++
+		}
+	}
+	if s.err == nil { // 未找到结束符
+		s.eofErr(s.parseIdx)
+	}
+}
@@ -0,0 +1,33 @@
+package pjson5
+
+import "strconv"
+
+// SchemaSkeleton 返回与文档结构相同的新文档，其中每个标量值替换为其类型名称的字符串，如 "Number"、"String"，
+// 数组只保留第一个元素作为元素类型的样例，空数组保持为空。注释与原文的换行会保留，作为编写schema时的说明。
+// 原节点不会被修改，任一节点解析失败时返回的节点记录该错误
+func (n *Node) SchemaSkeleton() *Node {
+	c := n.clone()
+	var err error
+	c.walk(nil, func(_ []string, node *Node) bool {
+		if err != nil {
+			return false
+		}
+		if err = node.parse().Error(); err != nil {
+			return false
+		}
+		switch node.typ {
+		case Array:
+			for i := len(node.children) - 1; i > 0; i-- {
+				node.deleteArrayNode(strconv.Itoa(i))
+			}
+		case Object:
+		default:
+			node.typ, node.val = String, quoteString(node.typ.String())
+		}
+		return true
+	})
+	if err != nil {
+		return &Node{err: err}
+	}
+	return c
+}
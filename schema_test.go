@@ -0,0 +1,42 @@
+package pjson5
+
+import "testing"
+
+func TestNode_SchemaSkeleton(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "document",
+			data: rawJson,
+			want: "{ // 首行注释\n  \"number_key\": \"Number\", // 人数\n  \"string_key\": /*key中注释*/\"String\", // 字符串类型后注释\n  \"array_key\": [ \"Number\"  ], // 数组类型\n  // 字典类型行注释\n  \"map_key\": {\n    // 字典类型首行注释\n    \"name\": \"String\", // 字典字符串\n    \"val\": \"Number\", // val\n    // array\n    \"data_list\": [ \"Number\"    ], \n  }, \n} // 尾行注释\n// 末尾注释\n",
+		},
+		{
+			name: "nested_arrays",
+			data: "{list: [{a: 1, b: [true, false]}, {a: 2}], empty: [], n: null}",
+			want: "{   list: [ {       a: \"Number\",\n      b: [ \"Boolean\"      ]    }  ],\n  empty: [   ],\n  n: \"Null\"}",
+		},
+		{name: "scalar", data: "'x' // c", want: `"String" // c`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.data)
+			before := node.Pretty()
+			got := node.SchemaSkeleton()
+			if got.Error() != nil {
+				t.Fatal("SchemaSkeleton() error:", got.Error())
+			}
+			if got.Pretty() != tt.want {
+				t.Fatalf("SchemaSkeleton() = %q, want %q", got.Pretty(), tt.want)
+			}
+			if node.Pretty() != before {
+				t.Fatal("expected the original node to be unchanged")
+			}
+		})
+	}
+	if New(`{a: [1, }`).SchemaSkeleton().Error() == nil {
+		t.Fatal("expected error for invalid document")
+	}
+}
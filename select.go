@@ -0,0 +1,67 @@
+package pjson5
+
+import (
+	"sort"
+	"strconv"
+)
+
+// selection 待保留路径组成的前缀树，all表示保留该节点下的全部内容
+type selection struct {
+	all      bool
+	children map[string]*selection
+}
+
+// Select 返回只包含指定路径及其祖先节点的新文档，保留值与注释，不存在的路径会被忽略。
+// 选择数组中的部分元素时，未选择的元素被删除，保留的元素重新编号。路径上的节点解析失败时返回的节点记录该错误
+func (n *Node) Select(paths ...string) *Node {
+	if n.parse().Error() != nil {
+		return &Node{err: n.err}
+	}
+	root := &selection{}
+	for _, path := range paths {
+		if _, ok := n.Lookup(path); !ok {
+			if n.err != nil { // 路径上的节点解析失败
+				return &Node{err: n.err}
+			}
+			continue // 不存在的路径直接忽略
+		}
+		sel := root
+		for _, key := range parsePath(path).PathNoe {
+			if sel.children == nil {
+				sel.children = make(map[string]*selection)
+			}
+			if sel.children[key] == nil {
+				sel.children[key] = &selection{}
+			}
+			sel = sel.children[key]
+		}
+		sel.all = true
+	}
+	c := n.clone()
+	c.prune(root)
+	return c
+}
+
+// prune 删除sel中未选择的子节点
+func (n *Node) prune(sel *selection) {
+	if sel.all || n.parse().Error() != nil {
+		return
+	}
+	var dropped []int
+	for _, key := range n.orderedKeys() {
+		if sub, ok := sel.children[key]; ok {
+			n.children[key].prune(sub)
+			continue
+		}
+		if n.typ == Array {
+			idx, _ := strconv.Atoi(key)
+			dropped = append(dropped, idx)
+			continue
+		}
+		n.deleteObjectNode(key, false)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(dropped))) // 从后向前删除，避免重新编号影响后续下标
+	for _, idx := range dropped {
+		n.deleteArrayNode(strconv.Itoa(idx))
+	}
+}
@@ -0,0 +1,47 @@
+package pjson5
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNode_Select(t *testing.T) {
+	node := New(rawJson)
+	selected := node.Select("number_key", "map_key.name", "map_key.data_list", "missing.path")
+	if selected.Error() != nil {
+		t.Fatal("select error:", selected.Error())
+	}
+	pretty := selected.Pretty()
+	for _, want := range []string{`"number_key": 2, // 人数`, "// 字典类型行注释", `"name": "This is name", // 字典字符串`, `"data_list": [`} {
+		if !strings.Contains(pretty, want) {
+			t.Fatalf("expected %q in selection:\n%s", want, pretty)
+		}
+	}
+	for _, absent := range []string{"string_key", "array_key", `"val"`, "missing"} {
+		if strings.Contains(pretty, absent) {
+			t.Fatalf("expected %q to be dropped:\n%s", absent, pretty)
+		}
+	}
+	if v := New(pretty).Get("map_key.data_list.0").Value(); v != "5000" {
+		t.Fatalf("expected selection to stay valid, got %q\n%s", v, pretty)
+	}
+	if !node.Exists("string_key") || !node.Exists("map_key.val") {
+		t.Fatal("expected original document to be unchanged")
+	}
+
+	arr := New(`{"list": [{"a": 1, "b": 2}, {"a": 3, "b": 4}, 5]}`).Select("list.1.a", "list.2")
+	if got := arr.Get("list").Len(); got != 2 {
+		t.Fatalf("expected 2 selected elements, got %d:\n%s", got, arr.Pretty())
+	}
+	if arr.Get("list.0.a").Value() != "3" || arr.Exists("list.0.b") || arr.Get("list.1").Value() != "5" {
+		t.Fatalf("unexpected array selection:\n%s", arr.Pretty())
+	}
+
+	bad := New(`{"a": {"b": tru}, "c": 1}`)
+	if err := bad.Select("c", "a.b").Error(); err == nil || !strings.Contains(err.Error(), "invalid JSON5 value") {
+		t.Fatalf("expected parse error from Select, got %v", err)
+	}
+	if bad.Error() == nil {
+		t.Fatal("expected the parse error to stay on the source node")
+	}
+}
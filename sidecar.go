@@ -0,0 +1,225 @@
+package pjson5
+
+// sidecar中注释锚点的后缀，不带后缀的路径表示成员之前独占一行的前置注释
+const (
+	anchorTrailing = "#"      // 成员值之后的同行注释
+	anchorInner    = "#inner" // key与值之间的注释
+	anchorStart    = "#start" // 起始符号同行、首个成员之前的注释
+	anchorEnd      = "#end"   // 最后一个成员之后、结束符号之前的注释
+	anchorHead     = "#head"  // 文档开头、根节点的值之前的注释（仅根节点）
+	anchorTail     = "#tail"  // 根节点的值之后的注释（仅根节点）
+)
+
+// SplitComments 将整棵树中的注释剥离到sidecar中，返回不含注释的副本。
+// sidecar的key为注释锚定的路径：成员路径表示其前置注释，路径加 "#" 表示值之后的同行注释，
+// 加 "#inner" 表示key与值之间的注释；容器路径加 "#start"/"#end" 表示首个成员之前和最后一个成员之后的注释，
+// 文档首尾的注释为 "#head" 与 "#tail"。同一锚点的多段注释按原文拼接。原节点不会被修改
+func (n *Node) SplitComments() (stripped *Node, sidecar map[string]string) {
+	if n.parse().Error() != nil {
+		return &Node{err: n.err}, nil
+	}
+	stripped = n.clone()
+	sidecar = make(map[string]string)
+	stripped.splitComments(nil, sidecar)
+	return stripped, sidecar
+}
+
+// ApplyComments 将SplitComments得到的sidecar中的注释重新插入到对应的位置，锚点不存在的注释会被忽略
+func (n *Node) ApplyComments(sidecar map[string]string) *Node {
+	if n.parse().Error() != nil {
+		return n
+	}
+	n.applyComments(nil, sidecar)
+	return n
+}
+
+// commentAnchor 返回path下的注释锚点，member为空时锚定在容器自身
+func commentAnchor(path []string, member, suffix string) string {
+	if member != "" {
+		path = append(path[:len(path):len(path)], member)
+	}
+	return joinPath(path) + suffix
+}
+
+func (n *Node) splitComments(path []string, sidecar map[string]string) {
+	if n.parse().Error() != nil {
+		return
+	}
+	isRoot := path == nil
+	container := n.typ == Object || n.typ == Array
+	blocks := make([]dataBlock, 0, len(n.block))
+	var member string
+	var started, ended, inMember bool
+	for i, block := range n.block {
+		switch block.Typ {
+		case dataTypeStartFlag:
+			started = true
+		case dataTypeEndFlag:
+			ended = true
+		case dataTypeKey:
+			member, inMember = block.KeyUnQuot(), true
+		case dataTypeVal:
+			if n.typ == Array {
+				member = block.Val
+			}
+			inMember = false
+			if child, ok := n.children[member]; ok && container {
+				child.splitComments(append(path[:len(path):len(path)], member), sidecar)
+			} else if !container {
+				started, ended = true, true
+			}
+		}
+		if !block.Is(dataTypeComment | dataTypeCommentLine) {
+			blocks = append(blocks, block)
+			continue
+		}
+		var anchor string
+		switch {
+		case isRoot && !started:
+			anchor = anchorHead
+		case isRoot && ended:
+			anchor = anchorTail
+		case n.ownLineComment(i):
+			if next := n.nextMember(i); next != "" {
+				anchor = commentAnchor(path, next, "")
+			} else {
+				anchor = commentAnchor(path, "", anchorEnd)
+			}
+		case inMember:
+			anchor = commentAnchor(path, member, anchorInner)
+		case member != "":
+			anchor = commentAnchor(path, member, anchorTrailing)
+		default:
+			anchor = commentAnchor(path, "", anchorStart)
+		}
+		sidecar[anchor] += block.Val
+		// 同行注释包含行尾的换行，删除后需保留换行
+		if !n.ownLineComment(i) && endsWithLineBreak(block) && started && !ended &&
+			!nextBlockIs(n, i, dataTypeLineBreak) {
+			blocks = append(blocks, dataBlock{Typ: dataTypeLineBreak})
+		}
+	}
+	n.block = blocks
+}
+
+// nextMember 返回idx之后、结束符号之前的下一个成员的key或下标，不存在时返回空
+func (n *Node) nextMember(idx int) string {
+	for _, block := range n.block[idx+1:] {
+		switch {
+		case block.Typ == dataTypeEndFlag:
+			return ""
+		case n.typ == Object && block.Typ == dataTypeKey:
+			return block.KeyUnQuot()
+		case n.typ == Array && block.Typ == dataTypeVal:
+			return block.Val
+		}
+	}
+	return ""
+}
+
+func (n *Node) applyComments(path []string, sidecar map[string]string) {
+	if n.parse().Error() != nil {
+		return
+	}
+	isRoot := path == nil
+	container := n.typ == Object || n.typ == Array
+	get := func(member, suffix string, typ int32) []dataBlock {
+		if isRoot && (suffix == anchorHead || suffix == anchorTail) {
+			return commentBlocks(sidecar[suffix], typ)
+		}
+		return commentBlocks(sidecar[commentAnchor(path, member, suffix)], typ)
+	}
+	blocks := make([]dataBlock, 0, len(n.block))
+	// appendInline 追加同行注释，注释自带换行时去掉其后原有的换行
+	skipLB := false
+	appendInline := func(comments []dataBlock) {
+		if len(comments) == 0 {
+			return
+		}
+		blocks = append(blocks, comments...)
+		skipLB = endsWithLineBreak(comments[len(comments)-1])
+	}
+	appendOwnLine := func(comments []dataBlock) {
+		if len(comments) == 0 {
+			return
+		}
+		if len(blocks) > 0 && !endsWithLineBreak(blocks[len(blocks)-1]) {
+			blocks = append(blocks, dataBlock{Typ: dataTypeLineBreak})
+		}
+		blocks = append(blocks, comments...)
+	}
+	head := 0
+	if isRoot {
+		head = n.headIndex()
+		blocks = append(blocks, n.block[:head]...)
+		blocks = append(blocks, get("", anchorHead, dataTypeCommentLine)...)
+	}
+	var member string
+	var trailing []dataBlock
+	for i, block := range n.block {
+		if i < head { // shebang行已写入
+			continue
+		}
+		if block.Typ == dataTypeLineBreak && skipLB {
+			skipLB = false
+			continue
+		}
+		skipLB = false
+		switch block.Typ {
+		case dataTypeKey:
+			member = block.KeyUnQuot()
+			appendOwnLine(get(member, "", dataTypeComment))
+		case dataTypeVal:
+			if n.typ == Array {
+				member = block.Val
+				appendOwnLine(get(member, "", dataTypeComment))
+			}
+		case dataTypeEndFlag:
+			appendOwnLine(get("", anchorEnd, dataTypeComment))
+		}
+		blocks = append(blocks, block)
+		switch block.Typ {
+		case dataTypeStartFlag:
+			appendInline(get("", anchorStart, dataTypeCommentLine))
+		case dataTypeColon:
+			appendInline(get(member, anchorInner, dataTypeCommentLine))
+		case dataTypeVal:
+			if child, ok := n.children[member]; ok && container {
+				child.applyComments(append(path[:len(path):len(path)], member), sidecar)
+			}
+			if container {
+				trailing = get(member, anchorTrailing, dataTypeCommentLine)
+			}
+		}
+		// 同行注释写在值之后的逗号后
+		if len(trailing) > 0 && !(block.Typ == dataTypeVal && nextBlockIs(n, i, dataTypeComma)) {
+			appendInline(trailing)
+			trailing = nil
+		}
+		if isRoot && (block.Typ == dataTypeEndFlag || (!container && block.Typ == dataTypeVal)) {
+			appendInline(get("", anchorTail, dataTypeCommentLine))
+		}
+	}
+	n.block = blocks
+}
+
+// commentBlocks 将sidecar中拼接的注释原文拆分为注释block，首段使用typ，其余段若位于行首则为独占一行的注释
+func commentBlocks(text string, typ int32) []dataBlock {
+	p := &Node{raw: text}
+	for {
+		p.parseIdx, _ = skipWhiteSpace(p.raw, p.parseIdx)
+		if p.parseIdx >= len(p.raw) || !p.except(backslash) {
+			break
+		}
+		if _, suc := p.parseComment(true, false); !suc || p.err != nil {
+			break
+		}
+	}
+	for i := range p.block {
+		p.block[i].Typ = typ
+		if i > 0 && endsWithLineBreak(p.block[i-1]) {
+			p.block[i].Typ = dataTypeComment
+		}
+	}
+	return p.block
+}
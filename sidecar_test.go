@@ -0,0 +1,90 @@
+package pjson5
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNode_SplitComments(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantSidecar map[string]string
+		wantApplied string
+	}{
+		{
+			name: "object",
+			data: "{ // start\n  \"a\": /* inner */1, // one\n  // lead\n  \"b\": {\n    \"c\": 2, // two\n  },\n} // tail\n",
+			wantSidecar: map[string]string{
+				"#start":  "// start\n",
+				"a#inner": "/* inner */",
+				"a#":      "// one\n",
+				"b":       "// lead\n",
+				"b.c#":    "// two\n",
+				"#tail":   "// tail\n",
+			},
+			wantApplied: "{ // start\n  \"a\": /* inner */1, // one\n  // lead\n  \"b\": {\n    \"c\": 2, // two\n  }, \n} // tail\n",
+		},
+		{
+			name: "array",
+			data: "[\n  // first\n  1, // one\n  2,\n  // end\n]",
+			wantSidecar: map[string]string{
+				"0":    "// first\n",
+				"0#":   "// one\n",
+				"#end": "// end\n",
+			},
+			wantApplied: "[\n  // first\n  1, // one\n  2, \n  // end\n]",
+		},
+		{
+			name: "scalar",
+			data: "// head\n1 // tail\n",
+			wantSidecar: map[string]string{
+				"#head": "// head\n",
+				"#tail": "// tail\n",
+			},
+			wantApplied: "// head\n1 // tail\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.data)
+			stripped, sidecar := node.SplitComments()
+			if !reflect.DeepEqual(sidecar, tt.wantSidecar) {
+				t.Fatalf("SplitComments() sidecar = %q, want %q", sidecar, tt.wantSidecar)
+			}
+			if got := stripped.Pretty(); strings.Contains(got, "//") || strings.Contains(got, "/*") {
+				t.Fatalf("SplitComments() stripped = %q, still contains comments", got)
+			}
+			if node.Pretty() == stripped.Pretty() {
+				t.Fatal("SplitComments() modified the original node")
+			}
+			if got := stripped.ApplyComments(sidecar).Pretty(); got != tt.wantApplied {
+				t.Fatalf("ApplyComments() = %q, want %q", got, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestNode_SplitCommentsShebang(t *testing.T) {
+	opts := ParseOptions{AllowShebang: true}
+	data := "#!/bin/app\n// head\n{   a: 1} // tail\n"
+	stripped, sidecar := NewWithOptions(data, opts).SplitComments()
+	if want := map[string]string{"#head": "// head\n", "#tail": "// tail\n"}; !reflect.DeepEqual(sidecar, want) {
+		t.Fatalf("SplitComments() sidecar = %q, want %q", sidecar, want)
+	}
+	got := stripped.ApplyComments(sidecar).Pretty()
+	if got != data {
+		t.Fatalf("ApplyComments() = %q, want %q", got, data)
+	}
+	if err := NewWithOptions(got, opts).Parse().Error(); err != nil {
+		t.Fatal("reparse error:", err)
+	}
+}
+
+func TestNode_ApplyCommentsUnknownAnchor(t *testing.T) {
+	got := New(`{"a": 1}`).ApplyComments(map[string]string{"b": "// b\n", "a#": "/* a */"}).Pretty()
+	if want := `{   "a": 1 /* a */}`; got != want {
+		t.Fatalf("ApplyComments() = %q, want %q", got, want)
+	}
+}
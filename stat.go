@@ -0,0 +1,45 @@
+package pjson5
+
+// Stats 文档的统计信息
+type Stats struct {
+	Keys     int          // 对象key的总数
+	MaxDepth int          // 最大嵌套深度，根节点为0
+	Objects  int          // 对象个数
+	Arrays   int          // 数组个数
+	Comments int          // 注释个数
+	Scalars  map[Type]int // 各类型标量值的个数
+	Bytes    int          // 原始文档的字节数
+}
+
+// Stat 遍历整棵树并返回文档的统计信息
+func (n *Node) Stat() Stats {
+	stats := Stats{Scalars: make(map[Type]int), Bytes: len(n.raw)}
+	n.walk(nil, func(path []string, node *Node) bool {
+		if node.parse().Error() != nil {
+			return false
+		}
+		stats.MaxDepth = max(stats.MaxDepth, len(path))
+		for _, block := range node.block {
+			if block.Is(dataTypeComment | dataTypeCommentLine) {
+				stats.Comments++
+			}
+		}
+		switch node.typ {
+		case Object:
+			stats.Objects++
+			stats.Keys += len(node.children)
+		case Array:
+			stats.Arrays++
+		default:
+			stats.Scalars[node.typ]++
+		}
+		return true
+	})
+	return stats
+}
+
+// ValueTypeHistogram 返回文档中各类型标量值的个数，与 Stat 的 Scalars 相同，只包含出现过的类型。
+// 可用于发现类型不符合预期的配置，如大量以字符串保存的数字
+func (n *Node) ValueTypeHistogram() map[Type]int {
+	return n.Stat().Scalars
+}
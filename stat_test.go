@@ -0,0 +1,36 @@
+package pjson5
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNode_Stat(t *testing.T) {
+	stats := New(rawJson).Stat()
+	want := Stats{
+		Keys:     7,
+		MaxDepth: 3,
+		Objects:  2,
+		Arrays:   2,
+		Comments: 12,
+		Bytes:    len(rawJson),
+	}
+	if stats.Keys != want.Keys || stats.MaxDepth != want.MaxDepth || stats.Objects != want.Objects ||
+		stats.Arrays != want.Arrays || stats.Comments != want.Comments || stats.Bytes != want.Bytes {
+		t.Fatalf("Stat() = %+v, want %+v", stats, want)
+	}
+	if stats.Scalars[Number] != 7 || stats.Scalars[String] != 2 || len(stats.Scalars) != 2 {
+		t.Fatalf("unexpected scalar counts: %v", stats.Scalars)
+	}
+}
+
+func TestNode_ValueTypeHistogram(t *testing.T) {
+	got := New(`{a: '1', b: "2", c: [3, null, true, {d: 'x'}], e: {}}`).ValueTypeHistogram()
+	want := map[Type]int{String: 3, Number: 1, Null: 1, Boolean: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ValueTypeHistogram() = %v, want %v", got, want)
+	}
+	if got := New(`{a: }`).ValueTypeHistogram(); len(got) != 0 {
+		t.Fatalf("ValueTypeHistogram() for invalid document = %v", got)
+	}
+}
@@ -0,0 +1,216 @@
+package pjson5
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CountValues 统计 data 中拼接在一起的顶层 JSON5 值的个数（如 `{...} {...} 1`），
+// 按与解析相同的规则逐个校验每个值（包括对象与数组内部的成员），但不构建节点树。
+// 遇到非法值时停止，返回此前已成功统计的个数及错误
+func CountValues(data []byte) (int, error) {
+	s := newScanner(&Node{raw: bytesToString(data)})
+	count := 0
+	for {
+		if s.space(); s.err != nil {
+			return count, s.err
+		}
+		if s.parseIdx >= len(s.raw) {
+			return count, nil
+		}
+		if s.value(); s.err != nil {
+			return count, s.err
+		}
+		count++
+	}
+}
+
+// NeedsMoreInput 判断节点是否因输入提前结束而未解析完整：开启 AllowPartial 时根据解析状态判断，
+// 否则判断解析错误是否为 ErrUnexpectedEOF
+func (n *Node) NeedsMoreInput() bool {
+	if n.parse().incomplete {
+		return true
+	}
+	return errors.Is(n.err, ErrUnexpectedEOF)
+}
+
+// Resume 在原始输入后追加more并重新解析，用于输入逐步到达的场景。当前节点上的修改会被丢弃
+func (n *Node) Resume(more string) *Node {
+	*n = Node{raw: n.raw + more, opts: n.opts, parent: n.parent}
+	return n.parse()
+}
+
+// Minify 从src中分块读取JSON5文本，删除注释与字符串之外的空白后写入dst，不构建节点树，
+// 内存占用只与最长的字符串或注释有关，与输入大小无关。字符串、数字等内容按原文输出，
+// 相邻的两个标识符或数字之间保留一个空格。空白、注释与字符串按与解析相同的规则识别，但Minify不校验语法，
+// 只在字符串或块注释未结束、出现非注释的 / 以及遇到非法UTF-8时返回 *ParseError
+func Minify(dst io.Writer, src io.Reader) error {
+	m := &minifier{r: src, w: bufio.NewWriter(dst), chunk: make([]byte, minifyChunkSize), n: &Node{}, line: 1, column: 1}
+	if err := m.run(); err != nil {
+		return err
+	}
+	return m.w.Flush()
+}
+
+const minifyChunkSize = 32 << 10
+
+// minifier 保存流式压缩的输入缓冲、读取位置与输出状态。
+// 缓冲中的内容通过n按解析时的规则识别，n.offset为缓冲在输入中的起始位置
+type minifier struct {
+	r       io.Reader
+	w       *bufio.Writer
+	chunk   []byte
+	eof     bool  // 是否已读取全部输入
+	n       *Node // 扫描缓冲的游标，raw为尚未处理完的输入
+	mark    int   // 已计算行列号的位置，相对于缓冲
+	line    int   // mark处的行号，从1开始
+	column  int   // mark处的列号，从1开始，按字符计算
+	afterCR bool  // mark之前的字符是否为 \r，用于将 \r\n 按一个换行计算
+	last    rune  // 最后输出的字符
+	skipped bool  // 上次输出之后是否跳过了空白或注释
+}
+
+func (m *minifier) run() error {
+	n := m.n
+	for {
+		if n.parseIdx >= len(n.raw) {
+			if m.eof {
+				return nil
+			}
+			if err := m.fill(); err != nil {
+				return err
+			}
+			continue
+		}
+		start, c := n.parseIdx, n.raw[n.parseIdx]
+		switch {
+		case c == '"' || c == '\'':
+			n.parseString()
+		case c == backslash:
+			if _, suc := n.parseComment(false, false); n.err == nil && !suc { // 不属于注释的 /
+				text := n.raw[start : start+1]
+				if n.raw[start+1] < utf8.RuneSelf {
+					text = n.raw[start : start+2]
+				}
+				msg := fmt.Sprintf(errParseJsonErrorTmpl, n.offset+start, text)
+				n.err = n.newParseError(start, msg, nil)
+			}
+		case c >= utf8.RuneSelf && !m.eof && !utf8.FullRuneInString(n.raw[start:]):
+			n.eofErr(len(n.raw)) // 多字节字符不完整
+		case c == '\r' && !m.eof && start+1 == len(n.raw):
+			n.eofErr(len(n.raw)) // 可能是 \r\n
+		default:
+			if l := lineBreakLen(n.raw, start) + whitespaceLen(n.raw, start); l > 0 {
+				n.parseIdx += l
+				m.skipped = true
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(n.raw[start:])
+			if r == utf8.RuneError && size == 1 {
+				return m.error((&Node{raw: n.raw[start : start+1], offset: n.offset + start}).ValidateUTF8())
+			}
+			m.write(r)
+			n.parseIdx += size
+			continue
+		}
+		// 字符串或注释在缓冲末尾未结束，读取更多输入后重新识别
+		if errors.Is(n.err, ErrUnexpectedEOF) && !m.eof || n.err == nil && c == backslash && n.raw[start+1] == backslash &&
+			!m.eof && n.parseIdx == len(n.raw) {
+			n.err, n.parseIdx = nil, start
+			if err := m.fill(); err != nil {
+				return err
+			}
+			continue
+		}
+		if n.err != nil {
+			return m.error(n.err)
+		}
+		token := n.raw[start:n.parseIdx]
+		if !utf8.ValidString(token) {
+			return m.error((&Node{raw: token, offset: n.offset + start}).ValidateUTF8())
+		}
+		if c == backslash {
+			m.skipped = true
+			continue
+		}
+		m.write(rune(c))
+		m.w.WriteString(token[1:])
+		m.last = rune(c)
+	}
+}
+
+// fill 丢弃缓冲中已处理的内容，并追加读取下一块输入
+func (m *minifier) fill() error {
+	n := m.n
+	m.position(n.parseIdx)
+	for {
+		size, err := m.r.Read(m.chunk)
+		if err == io.EOF {
+			m.eof = true
+		} else if err != nil {
+			return err
+		}
+		if size > 0 || m.eof {
+			n.raw = n.raw[n.parseIdx:] + string(m.chunk[:size])
+			n.offset += n.parseIdx
+			m.mark -= n.parseIdx
+			n.parseIdx = 0
+			return nil
+		}
+	}
+}
+
+// position 计算缓冲中pos处的行号与列号，pos不能小于此前计算过的位置
+func (m *minifier) position(pos int) (line, column int) {
+	raw := m.n.raw
+	for i := m.mark; i < pos; {
+		size := lineBreakLen(raw, i)
+		switch {
+		case size > 0 && raw[i] == '\n' && m.afterCR:
+		case size > 0:
+			m.line, m.column = m.line+1, 1
+		default:
+			_, size = utf8.DecodeRuneInString(raw[i:])
+			m.column++
+		}
+		m.afterCR = raw[i] == '\r'
+		i += size
+	}
+	m.mark = max(m.mark, pos)
+	return m.line, m.column
+}
+
+// write 输出字符，被空白或注释隔开的两个标识符/数字之间补一个空格
+func (m *minifier) write(r rune) {
+	if m.skipped && isMinifyWord(m.last) && isMinifyWord(r) {
+		m.w.WriteByte(' ')
+	}
+	m.w.WriteRune(r)
+	m.last, m.skipped = r, false
+}
+
+// error 将缓冲中识别出的错误的行列号换算为在整个输入中的行列号
+func (m *minifier) error(err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		pe.Line, pe.Column = m.position(pe.Offset - m.n.offset)
+	}
+	return err
+}
+
+// isMinifyWord 判断字符是否属于标识符、关键字或数字，这类字符相邻时不能合并
+func isMinifyWord(r rune) bool {
+	return r == '_' || r == '$' || r == '.' || r == '+' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Validate 检查data是否为合法的JSON5文档，返回逐层检查时遇到的第一个错误（包含行列号的 *ParseError）。
+// 与 New(...).Parse() 只解析根节点不同，Validate 会检查整棵树，结果与使用默认选项逐层解析整棵树相同：
+// 直接在输入上扫描并丢弃结果，不创建节点，也不记录block，耗时与内存占用远小于完整解析
+func Validate(data []byte) error {
+	s := newScanner(&Node{raw: bytesToString(data)})
+	s.document()
+	return s.err
+}
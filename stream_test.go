@@ -0,0 +1,199 @@
+package pjson5
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCountValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", data: "", want: 0},
+		{name: "blank_and_comments", data: " // only comment\n /* block */ ", want: 0},
+		{name: "single_object", data: rawJson, want: 1},
+		{name: "concatenated", data: "{\"a\": 1} [1, 2]\n'x' // c\n 3 /* c */ null", want: 5},
+		{name: "url_in_string", data: `{"u": "https://x.com/}"}{"v": [1]}`, want: 2},
+		{name: "malformed_second", data: `{"a": 1} [1, 2 `, want: 1, wantErr: true},
+		{name: "malformed_scalar", data: `1 2 tru 4`, want: 2, wantErr: true},
+		{name: "missing_member_value", data: `{a: } 1`, want: 0, wantErr: true},
+		{name: "empty_array_element", data: `[1,,2] 3`, want: 0, wantErr: true},
+		{name: "malformed_member", data: `{a: tru}`, want: 0, wantErr: true},
+		{name: "malformed_nested", data: `[1] {a: [{b: 1, b: 2}]} 2`, want: 1, wantErr: true},
+		{name: "nested_comments", data: "{a: [1, /* ] */ 2], // }\n b: {}}\n[]", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CountValues([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CountValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("CountValues() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr error
+	}{
+		{name: "comments_and_spaces", src: rawJson, want: `{"number_key":2,"string_key":"www.com","array_key":[1,2,3,4],"map_key":{"name":"This is name","val":60000,"data_list":[5000],},}`},
+		{name: "strings_kept", src: "{ a : 'it\\'s // not /* a comment */', b: \"x  y\" }", want: "{a:'it\\'s // not /* a comment */',b:\"x  y\"}"},
+		{name: "separate_words", src: "1 /* c */ 2\ntrue\u00a0null", want: "1 2 true null"},
+		{name: "line_comment_at_eof", src: "[1, 2] // end", want: "[1,2]"},
+		{name: "unterminated_string", src: `{"a": "b`, wantErr: ErrUnexpectedEOF},
+		{name: "unterminated_comment", src: `{"a": 1 /* x`, wantErr: ErrUnexpectedEOF},
+		{name: "invalid_utf8", src: "{\"a\": \"\xff\"}", wantErr: ErrInvalidUTF8},
+		{name: "unicode_spaces", src: "[1,\u20282\u3000, /*\u2029*/ '\u4e2d']\r\n", want: "[1,2,'\u4e2d']"},
+		{name: "crlf_comment", src: "[1, // c\r\n 2]", want: "[1,2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oneByte := &strings.Builder{} // 每次只读取一个字节，覆盖字符串、注释与多字节字符跨越读取边界的情况
+			oneByteErr := Minify(oneByte, iotest.OneByteReader(strings.NewReader(tt.src)))
+			buf := &strings.Builder{}
+			err := Minify(buf, strings.NewReader(tt.src))
+			if buf.String() != oneByte.String() || fmt.Sprint(err) != fmt.Sprint(oneByteErr) {
+				t.Fatalf("Minify() with one byte reads = %q, %v, want %q, %v", oneByte, oneByteErr, buf, err)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Minify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Minify() error:", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("Minify() = %s, want %s", got, tt.want)
+			}
+			if _, err := CountValues([]byte(buf.String())); err != nil {
+				t.Fatal("minified output is not valid JSON5:", err)
+			}
+		})
+	}
+	if err := Minify(io.Discard, strings.NewReader("{a: 1 / 2}")); err == nil {
+		t.Fatal("expected error for stray slash")
+	}
+	var pe *ParseError
+	err := Minify(io.Discard, iotest.OneByteReader(strings.NewReader("{\r\n  a: 1,\r\n  b: '\u4e2d\xff'}")))
+	if !errors.As(err, &pe) || pe.Line != 3 || pe.Column != 8 || !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("Minify() error = %v, want invalid UTF-8 at line 3, column 8", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantLine int // 0 表示合法
+		wantErr  error
+	}{
+		{name: "sample", data: rawJson},
+		{name: "scalar", data: "'x' // c"},
+		{name: "nested_invalid", data: "{\n  \"a\": {\n    \"b\": [1, tru]\n  }\n}", wantLine: 3},
+		{name: "first_error_wins", data: "{\n  \"a\": [1,,2],\n  \"b\": {\"c\": x}\n}", wantLine: 2},
+		{name: "duplicate_key", data: "{\"a\": {\n  \"b\": 1, \"b\": 2}}", wantLine: 2},
+		{name: "truncated", data: `{"a": [1, 2`, wantLine: 1, wantErr: ErrUnexpectedEOF},
+		{name: "empty", data: "", wantLine: 1, wantErr: ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.data))
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Fatal("Validate() error:", err)
+				}
+				return
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.wantLine {
+				t.Fatalf("Validate() error = %v, want error at line %d", err, tt.wantLine)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateMatchesParse Validate 的结果应与逐层解析整棵树时遇到的第一个错误相同
+func TestValidateMatchesParse(t *testing.T) {
+	tests := []string{
+		`{a: 1, b: [1, 2, {c: 'x'}], d: {e: null}}`,
+		`{"a}": [']', /* ] */ {b: "{"}], // }` + "\n" + ` c: [[1], [2, {d: []}]]}`,
+		`{a: 1, b: [1, 2, {{c: 'x'}], d} {[e: null}}`,
+		`{a: {b: {c: d: 1}}, 'x y':: "z"n}`,
+		`{a: 1, b: [1, n2, {c: 'x'}], d: ` + "\n" + `{e: nul}`,
+		`[1, [2, [3, {a: [4]]], '/c 5,]`,
+		`[1, [2, [3, a: [4]}]], 5]`,
+		`[1, [2, [3, {a[4]}]], 5]`,
+		`{a: {b: 1, b: 2}, c: [1,, 2]}`,
+		`[{a: tru}, {b: 1} 2]`,
+	}
+	for _, data := range tests {
+		var want error
+		New(data).walk(nil, func(_ []string, node *Node) bool {
+			if want == nil {
+				want = node.parse().Error()
+			}
+			return want == nil
+		})
+		got := Validate([]byte(data))
+		if (got == nil) != (want == nil) || got != nil && got.Error() != want.Error() {
+			t.Errorf("Validate(%q) = %v, want %v", data, got, want)
+		}
+	}
+}
+
+// benchDocument 生成包含多层嵌套的对象与数组的文档
+func benchDocument() []byte {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "  // 服务%d\n  \"svc%d\": {name: 'gw', port: 0x50, tags: ['a', \"b\", 3.5e2], nested: {ok: true, v: null}},\n", i, i)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func BenchmarkValidate(b *testing.B) {
+	data := benchDocument()
+	b.Run("Validate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := Validate(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ParseRoot", func(b *testing.B) { // 只解析根节点，不检查嵌套的成员
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := New(string(data)).parse().Error(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ParseTree", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !New(string(data)).parseTree() {
+				b.Fatal("parseTree() failed")
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package pjson5
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MarshalJSON 实现 json.Marshaler，输出不含注释与多余空白的标准JSON：key统一使用双引号，
+// 单引号字符串转换为双引号，十六进制/八进制等数字转换为十进制。Infinity与NaN无法用JSON表示，返回错误
+func (n *Node) MarshalJSON() ([]byte, error) {
+	s, err := n.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// JSON 返回节点的标准JSON文本，与 MarshalJSON 输出相同：不含注释与尾随逗号，key统一使用双引号，
+// 单引号字符串转换为双引号，十六进制/八进制数字转换为十进制。无法转换时返回错误
+func (n *Node) JSON() (string, error) {
+	buf := &strings.Builder{}
+	if err := writeStrictJSON(buf, n, nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// LogValue 实现 slog.LogValuer，以紧凑的标准JSON记录节点，JSONHandler会将其作为嵌套的JSON值输出。
+// 节点无法转换为JSON时记录错误信息
+func (n *Node) LogValue() slog.Value {
+	data, err := n.MarshalJSON()
+	if err != nil {
+		return slog.StringValue(err.Error())
+	}
+	return slog.AnyValue(json.RawMessage(data))
+}
+
+// writeStrictJSON 将节点按文档顺序输出为紧凑的标准JSON，path用于错误信息
+func writeStrictJSON(buf *strings.Builder, node *Node, path []string) error {
+	if node.parse().Error() != nil {
+		return node.err
+	}
+	switch node.typ {
+	case Object, Array:
+		pair := objectPair
+		if node.typ == Array {
+			pair = arrayPair
+		}
+		buf.WriteByte(pair[0])
+		for i, key := range node.orderedKeys() {
+			if i > 0 {
+				buf.WriteByte(comma)
+			}
+			if node.typ == Object {
+				buf.WriteString(quoteString(key))
+				buf.WriteByte(colon)
+			}
+			if err := writeStrictJSON(buf, node.children[key], append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(pair[1])
+	case String:
+		s, err := unquote(node.val)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(quoteString(s))
+	case Number:
+		num, err := strictNumber(node.val)
+		if err != nil {
+			return fmt.Errorf("%w at %s", err, joinPath(path))
+		}
+		buf.WriteString(num)
+	case Boolean:
+		buf.WriteString(node.val)
+	case Null:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unsupported value at %s", joinPath(path))
+	}
+	return nil
+}
+
+// strictNumber 将JSON5数字字面量转换为标准JSON的数字，符合JSON语法的十进制数字保持原文
+func strictNumber(literal string) (string, error) {
+	s := trimLeadingZeros(strings.TrimPrefix(strings.ReplaceAll(literal, "_", ""), "+"))
+	if json.Valid([]byte(s)) {
+		return s, nil
+	}
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+	if u, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return strconv.FormatUint(u, 10), nil
+	}
+	f, ok := parseNumberLiteral(s)
+	if !ok {
+		return "", fmt.Errorf("invalid number %s", literal)
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("number %s cannot be represented in JSON", literal)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
@@ -0,0 +1,73 @@
+package pjson5
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNode_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		wantErr string
+	}{
+		{data: rawJson, want: `{"number_key":2,"string_key":"www.com","array_key":[1,2,3,4],"map_key":{"name":"This is name","val":60000,"data_list":[5000]}}`},
+		{data: `{a: 'it\'s', 'b<': [0xFF, +1, .5, 5., 1_0], c: NULL, d: true,}`, want: `{"a":"it's","b<":[255,1,0.5,5,10],"c":null,"d":true}`},
+		{data: `{a: 010, b: -007, c: 00}`, want: `{"a":10,"b":-7,"c":0}`},
+		{data: `"xA"`, want: `"xA"`},
+		{data: `{a: [1, Infinity]}`, wantErr: "cannot be represented in JSON at a.1"},
+		{data: `{a: tru}`, wantErr: "invalid JSON5 value"},
+	}
+	for _, tt := range tests {
+		node := NewWithOptions(tt.data, ParseOptions{AllowNumberUnderscore: true, LenientNull: true})
+		got, err := node.MarshalJSON()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("MarshalJSON(%s) error = %v, want %q", tt.data, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.want {
+			t.Fatalf("MarshalJSON(%s) = %s, %v, want %s", tt.data, got, err, tt.want)
+		}
+	}
+	// 作为其他值的一部分序列化
+	data, err := json.Marshal(map[string]*Node{"cfg": New(`{a: 1, /* c */ b: [2]}`)})
+	if err != nil || string(data) != `{"cfg":{"a":1,"b":[2]}}` {
+		t.Fatalf("json.Marshal() = %s, %v", data, err)
+	}
+}
+
+func TestNode_JSON(t *testing.T) {
+	got, err := New(`// 配置
+{
+  name: 'it\'s', // 名称
+  "list": [0x1F, 0o17, /* 注释 */ 3,],
+  nested: {ok: true, none: null,},
+}`).JSON()
+	want := `{"name":"it's","list":[31,15,3],"nested":{"ok":true,"none":null}}`
+	if err != nil || got != want {
+		t.Fatalf("JSON() = %s, %v, want %s", got, err, want)
+	}
+	if _, err := New(`{a: NaN}`).JSON(); err == nil {
+		t.Fatal("expected error for NaN")
+	}
+}
+
+func TestNode_LogValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+	logger.Info("config", "map", New(rawJson).Get("map_key"), "bad", New(`{a: NaN}`))
+	want := `{"level":"INFO","msg":"config","map":{"name":"This is name","val":60000,"data_list":[5000]},"bad":"number NaN cannot be represented in JSON at a"}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("log output = %s, want %s", buf.String(), want)
+	}
+}
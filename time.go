@@ -0,0 +1,82 @@
+package pjson5
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Duration 将字符串按 time.ParseDuration 解析为时长，如 "30s"、"1h30m"；数字视为秒数，如 1.5 表示1.5秒。
+// 其他类型、无法解析的字符串以及超出范围的数字返回错误
+func (n *Node) Duration() (time.Duration, error) {
+	if n.parse().Error() != nil {
+		return 0, n.err
+	}
+	switch n.typ {
+	case String:
+		s, err := unquote(n.val)
+		if err != nil {
+			return 0, err
+		}
+		return time.ParseDuration(s)
+	case Number:
+		f, err := n.Float64()
+		if err != nil {
+			return 0, err
+		}
+		d := f * float64(time.Second)
+		if math.IsNaN(d) || d >= math.MaxInt64 || d < math.MinInt64 {
+			return 0, fmt.Errorf("duration out of range: %s", n.val)
+		}
+		return time.Duration(d), nil
+	}
+	return 0, fmt.Errorf("value is %s, not a duration", n.typ)
+}
+
+// GetDuration 获取path对应节点的时长，path不存在时返回可通过 errors.Is(err, ErrPathNotFound) 识别的错误
+func (n *Node) GetDuration(path string) (time.Duration, error) {
+	node, err := n.lookupValue(path)
+	if err != nil {
+		return 0, err
+	}
+	return node.Duration()
+}
+
+// Time 将字符串按layout解析为时间，layout为空时使用 time.RFC3339。非字符串节点或无法解析的字符串返回错误
+func (n *Node) Time(layout string) (time.Time, error) {
+	if n.parse().Error() != nil {
+		return time.Time{}, n.err
+	}
+	if n.typ != String {
+		return time.Time{}, fmt.Errorf("value is %s, not String", n.typ)
+	}
+	s, err := unquote(n.val)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return time.Parse(layout, s)
+}
+
+// GetTime 获取path对应节点的时间，path不存在时返回可通过 errors.Is(err, ErrPathNotFound) 识别的错误
+func (n *Node) GetTime(path, layout string) (time.Time, error) {
+	node, err := n.lookupValue(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return node.Time(layout)
+}
+
+// lookupValue 获取path对应的节点，不存在时返回 ErrPathNotFound
+func (n *Node) lookupValue(path string) (*Node, error) {
+	node, ok := n.Lookup(path)
+	if n.err != nil {
+		return nil, n.err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+	return node, nil
+}
@@ -0,0 +1,76 @@
+package pjson5
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNode_Duration(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{data: `"30s"`, want: 30 * time.Second},
+		{data: `'1h30m'`, want: 90 * time.Minute},
+		{data: `"-250ms"`, want: -250 * time.Millisecond},
+		{data: `5`, want: 5 * time.Second},
+		{data: `1.5`, want: 1500 * time.Millisecond},
+		{data: `0x10`, want: 16 * time.Second},
+		{data: `"5 minutes"`, wantErr: true},
+		{data: `Infinity`, wantErr: true},
+		{data: `1e20`, wantErr: true},
+		{data: `true`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Duration()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Duration(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("Duration(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+	node := New(`{server: {timeout: "5m", /* 秒 */ retry: 3}}`)
+	if d, err := node.GetDuration("server.timeout"); err != nil || d != 5*time.Minute {
+		t.Fatalf("GetDuration() = %v, %v", d, err)
+	}
+	if d, err := node.GetDuration("server.retry"); err != nil || d != 3*time.Second {
+		t.Fatalf("GetDuration() = %v, %v", d, err)
+	}
+	if _, err := node.GetDuration("server.missing"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("GetDuration() error = %v", err)
+	}
+}
+
+func TestNode_Time(t *testing.T) {
+	want := time.Date(2026, 3, 1, 8, 30, 0, 0, time.FixedZone("", 8*3600))
+	tests := []struct {
+		data    string
+		layout  string
+		want    time.Time
+		wantErr bool
+	}{
+		{data: `"2026-03-01T08:30:00+08:00"`, want: want},
+		{data: `'2026-03-01'`, layout: time.DateOnly, want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{data: `"2026-03-01"`, wantErr: true},
+		{data: `20260301`, layout: "20060102", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.data).Time(tt.layout)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Time(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+		}
+		if !got.Equal(tt.want) {
+			t.Fatalf("Time(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+	node := New(`{license: {valid_until: "2026-03-01T08:30:00+08:00"}}`)
+	if got, err := node.GetTime("license.valid_until", ""); err != nil || !got.Equal(want) {
+		t.Fatalf("GetTime() = %v, %v", got, err)
+	}
+	if _, err := node.GetTime("license.issued", ""); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("GetTime() error = %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package pjson5
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ToTOML 将文档转换为TOML：对象转换为表，元素均为对象的数组转换为表数组，其余数组转换为行内数组。
+// key之前的注释与值之后的同行注释转换为 # 注释，无法锚定的注释（如数组元素的注释）会被丢弃。
+// 根节点必须是对象，null值及元素类型不一致的数组无法用TOML表示，返回包含其路径的错误
+func (n *Node) ToTOML() ([]byte, error) {
+	if n.parse().Error() != nil {
+		return nil, n.err
+	}
+	if n.typ != Object {
+		return nil, fmt.Errorf("toml: root must be an object, got %s", n.typ)
+	}
+	buf := &strings.Builder{}
+	// 文档开头以及与根对象起始符同行的注释输出在最前面
+	for i, block := range n.block {
+		if block.Typ == dataTypeKey || n.ownLineComment(i) && len(n.children) > 0 {
+			break
+		}
+		if block.Is(dataTypeComment | dataTypeCommentLine) {
+			writeTOMLComment(buf, block.Val)
+		}
+	}
+	if err := writeTOMLTable(buf, n, nil, nil); err != nil {
+		return nil, err
+	}
+	if comments := n.TrailingComments(); len(comments) > 0 {
+		buf.WriteString(lineBreak)
+		for _, comment := range comments {
+			writeTOMLComment(buf, comment)
+		}
+	}
+	return []byte(buf.String()), nil
+}
+
+// writeTOMLTable 输出对象中的成员：先输出普通的键值对，再输出子表与表数组。
+// table为表头使用的key路径（不含表数组的下标），path为错误信息使用的完整路径
+func writeTOMLTable(buf *strings.Builder, node *Node, table, path []string) error {
+	keys := node.orderedKeys()
+	var tables []string
+	for _, key := range keys {
+		child := node.children[key]
+		if child.parse().Error() != nil {
+			return child.err
+		}
+		if child.typ == Object || isArrayOfTables(child) {
+			tables = append(tables, key)
+			continue
+		}
+		leading, trailing := tomlKeyComments(node, key)
+		for _, comment := range leading {
+			writeTOMLComment(buf, comment.Val)
+		}
+		childPath := append(path[:len(path):len(path)], key)
+		val, err := tomlValue(child, childPath)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(tomlKey(key) + " = " + val)
+		writeTOMLTrailing(buf, trailing)
+		buf.WriteString(lineBreak)
+	}
+	for _, key := range tables {
+		child := node.children[key]
+		childPath := append(path[:len(path):len(path)], key)
+		childTable := append(table[:len(table):len(table)], key)
+		header := make([]string, len(childTable))
+		for i, p := range childTable {
+			header[i] = tomlKey(p)
+		}
+		leading, trailing := tomlKeyComments(node, key)
+		if buf.Len() > 0 {
+			buf.WriteString(lineBreak)
+		}
+		for _, comment := range leading {
+			writeTOMLComment(buf, comment.Val)
+		}
+		if child.typ == Object {
+			buf.WriteString("[" + strings.Join(header, ".") + "]")
+			writeTOMLTrailing(buf, trailing)
+			buf.WriteString(lineBreak)
+			if err := writeTOMLTable(buf, child, childTable, childPath); err != nil {
+				return err
+			}
+			continue
+		}
+		for i, elem := range child.orderedChildren() {
+			if i > 0 {
+				buf.WriteString(lineBreak)
+			}
+			buf.WriteString("[[" + strings.Join(header, ".") + "]]")
+			if i == 0 {
+				writeTOMLTrailing(buf, trailing)
+			}
+			buf.WriteString(lineBreak)
+			if err := writeTOMLTable(buf, elem, childTable, append(childPath[:len(childPath):len(childPath)], strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isArrayOfTables 判断节点是否为元素均为对象的非空数组
+func isArrayOfTables(node *Node) bool {
+	if node.typ != Array || len(node.children) == 0 {
+		return false
+	}
+	for _, elem := range node.children {
+		if elem.parse().typ != Object {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlKeyComments 返回key的前置注释，以及key与值之间、值之后的同行注释
+func tomlKeyComments(node *Node, key string) (leading, trailing []dataBlock) {
+	leading, inner, trailing := node.keyComments(key)
+	return leading, append(inner, trailing...)
+}
+
+func writeTOMLComment(buf *strings.Builder, raw string) {
+	for _, line := range strings.Split(CommentText(raw), lineBreak) {
+		buf.WriteString(strings.TrimRight("# "+line, " ") + lineBreak)
+	}
+}
+
+func writeTOMLTrailing(buf *strings.Builder, comments []dataBlock) {
+	texts := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		if text := CommentText(comment.Val); text != "" {
+			texts = append(texts, strings.ReplaceAll(text, lineBreak, " "))
+		}
+	}
+	if len(texts) > 0 {
+		buf.WriteString(" # " + strings.Join(texts, " "))
+	}
+}
+
+// tomlValue 将节点转换为TOML的值，对象转换为行内表
+func tomlValue(node *Node, path []string) (string, error) {
+	if node.parse().Error() != nil {
+		return "", node.err
+	}
+	switch node.typ {
+	case Null:
+		return "", fmt.Errorf("toml: null is not supported at %s", joinPath(path))
+	case Boolean:
+		return node.val, nil
+	case String:
+		s, err := unquote(node.val)
+		if err != nil {
+			return "", err
+		}
+		return tomlString(s), nil
+	case Number:
+		return tomlNumber(node.val)
+	case Array:
+		var elemTyp Type
+		items := make([]string, 0, len(node.children))
+		for i, elem := range node.orderedChildren() {
+			elemPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if i == 0 {
+				elemTyp = elem.parse().typ
+			} else if elem.parse().typ != elemTyp {
+				return "", fmt.Errorf("toml: mixed-type array at %s: %s and %s", joinPath(path), elemTyp, elem.typ)
+			}
+			item, err := tomlValue(elem, elemPath)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case Object:
+		items := make([]string, 0, len(node.children))
+		for _, key := range node.orderedKeys() {
+			item, err := tomlValue(node.children[key], append(path[:len(path):len(path)], key))
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(key)+" = "+item)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("toml: unsupported value at %s", joinPath(path))
+}
+
+// tomlNumber 将JSON5数字字面量转换为TOML的整数或浮点数
+func tomlNumber(literal string) (string, error) {
+	if (&Node{typ: Number, val: literal, parsed: true}).IsIntegral() {
+		if i, err := strconv.ParseInt(trimLeadingZeros(strings.TrimPrefix(strings.ReplaceAll(literal, "_", ""), "+")), 0, 64); err == nil {
+			return strconv.FormatInt(i, 10), nil
+		}
+		r, _ := new(big.Rat).SetString(strings.ReplaceAll(literal, "_", ""))
+		return r.Num().String(), nil
+	}
+	f, ok := parseNumberLiteral(literal)
+	if !ok {
+		return "", fmt.Errorf("toml: invalid number %s", literal)
+	}
+	switch {
+	case math.IsNaN(f):
+		return "nan", nil
+	case math.IsInf(f, 1):
+		return "inf", nil
+	case math.IsInf(f, -1):
+		return "-inf", nil
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s, nil
+}
+
+// tomlKey 输出TOML的key，只包含字母、数字、下划线和连字符的key使用裸key，其余使用带引号的key
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return tomlString(key)
+		}
+	}
+	return key
+}
+
+// tomlString 输出TOML的基本字符串，转义引号、反斜杠与控制字符
+func tomlString(s string) string {
+	buf := &strings.Builder{}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(buf, `\u%04X`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -0,0 +1,97 @@
+package pjson5
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNode_ToTOML(t *testing.T) {
+	got, err := New(rawJson).ToTOML()
+	if err != nil {
+		t.Fatal("ToTOML error:", err)
+	}
+	want := `# 首行注释
+number_key = 2 # 人数
+string_key = "www.com" # key中注释 字符串类型后注释
+array_key = [1, 2, 3, 4] # 数组类型
+
+# 字典类型行注释
+[map_key]
+# 字典类型首行注释
+name = "This is name" # 字典字符串
+val = 60000 # val
+# array
+data_list = [5000]
+
+# 尾行注释
+# 末尾注释
+`
+	if string(got) != want {
+		t.Fatalf("ToTOML() = %q, want %q", got, want)
+	}
+
+	got, err = New(`{
+  /** 服务列表 */
+  servers: [{name: 'a', port: 0x50}, {name: "b\"", tags: []}],
+  "a.b": {ratio: 1.50, big: 1e3, inf: -Infinity, point: {x: 1}},
+  ok: true,
+}`).ToTOML()
+	if err != nil {
+		t.Fatal("ToTOML error:", err)
+	}
+	want = `ok = true
+
+# 服务列表
+[[servers]]
+name = "a"
+port = 80
+
+[[servers]]
+name = "b\""
+tags = []
+
+["a.b"]
+ratio = 1.5
+big = 1000
+inf = -inf
+
+["a.b".point]
+x = 1
+`
+	if string(got) != want {
+		t.Fatalf("ToTOML() = %q, want %q", got, want)
+	}
+
+	// 表数组元素中的子表，表头不含下标；以0开头的十进制数字按十进制转换
+	got, err = New(`{a: 010, servers: [{name: "a", meta: {zone: "z"}}]}`).ToTOML()
+	if err != nil {
+		t.Fatal("ToTOML error:", err)
+	}
+	want = `a = 10
+
+[[servers]]
+name = "a"
+
+[servers.meta]
+zone = "z"
+`
+	if string(got) != want {
+		t.Fatalf("ToTOML() = %q, want %q", got, want)
+	}
+
+	errTests := []struct {
+		data string
+		want string
+	}{
+		{data: `[1]`, want: "root must be an object"},
+		{data: `{a: {b: null}}`, want: "null is not supported at a.b"},
+		{data: `{a: [1, "x"]}`, want: "mixed-type array at a"},
+		{data: `{a: [{b: 1}, 2]}`, want: "mixed-type array at a"},
+		{data: `{a: [{b: 1}, {c: {d: null}}]}`, want: "null is not supported at a.1.c.d"},
+	}
+	for _, tt := range errTests {
+		if _, err := New(tt.data).ToTOML(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("ToTOML(%s) error = %v, want %q", tt.data, err, tt.want)
+		}
+	}
+}
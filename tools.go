@@ -1,30 +1,127 @@
 package pjson5
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
 )
 
 func skipWhiteSpace(s string, pos int) (int, bool) {
 	containsLineBreaker := false
 	for pos < len(s) {
-		isLB := isLineBreaker(s[pos])
-		containsLineBreaker = containsLineBreaker || isLB
-		if !isLB && !isWhitespaceNLB(s[pos]) {
+		if l := lineBreakLen(s, pos); l > 0 {
+			containsLineBreaker = true
+			pos += l
+			continue
+		}
+		l := whitespaceLen(s, pos)
+		if l == 0 {
 			break
 		}
-		pos++
+		pos += l
 	}
 	return pos, containsLineBreaker
 }
 
 func skipLineWhiteSpace(s string, pos int) int {
-	for pos < len(s) && isWhitespaceNLB(s[pos]) {
-		pos++
+	for pos < len(s) {
+		l := whitespaceLen(s, pos)
+		if l == 0 {
+			break
+		}
+		pos += l
 	}
 	return pos
 }
 
+// whitespaceLen 返回s[pos]处的非换行空白字符的字节长度，不是空白字符时返回0。
+// 与JSON5一致，除空格与制表符外还包括 \v、\f、U+00A0、BOM 以及Unicode空格分隔符(Zs)
+func whitespaceLen(s string, pos int) int {
+	if c := s[pos]; c < utf8.RuneSelf {
+		if isWhitespaceNLB(c) || c == '\v' || c == '\f' {
+			return 1
+		}
+		return 0
+	}
+	r, size := utf8.DecodeRuneInString(s[pos:])
+	if r == '\u00a0' || r == '\ufeff' || unicode.Is(unicode.Zs, r) {
+		return size
+	}
+	return 0
+}
+
+// lineBreakLen 返回s[pos]处换行符的字节长度，包括 \r、\n 以及 U+2028、U+2029，不是换行符时返回0
+func lineBreakLen(s string, pos int) int {
+	if isLineBreaker(s[pos]) {
+		return 1
+	}
+	if strings.HasPrefix(s[pos:], "\u2028") || strings.HasPrefix(s[pos:], "\u2029") {
+		return len("\u2028")
+	}
+	return 0
+}
+
+// indexLineBreak 返回s中从from开始的第一个换行符（\n、U+2028、U+2029）的位置及其字节长度，未找到时返回-1
+func indexLineBreak(s string, from int) (int, int) {
+	for i := from; i < len(s); i++ {
+		if l := lineBreakLen(s, i); l > 0 && s[i] != '\r' {
+			return i, l
+		}
+	}
+	return -1, 0
+}
+
+// hasLineBreakSuffix 判断s是否以换行符结尾
+func hasLineBreakSuffix(s string) bool {
+	return strings.HasSuffix(s, lineBreak) || strings.HasSuffix(s, "\u2028") || strings.HasSuffix(s, "\u2029")
+}
+
+// lineColumn 返回s中第offset个字节所在的行号与列号（均从1开始，列号按字符计算）。
+// \n、\r\n、\r、U+2028、U+2029 均视为换行
+func lineColumn(s string, offset int) (line, column int) {
+	line, column = 1, 1
+	for i := 0; i < len(s) && i < offset; {
+		l := lineBreakLen(s, i)
+		switch {
+		case l > 0 && s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n':
+			i++ // \r\n 按一个换行计算
+		case l > 0:
+			line, column = line+1, 1
+			i += l
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			column++
+			i += size
+		}
+	}
+	return line, column
+}
+
+// lineAt 返回s中第offset个字节所在的整行内容，不包含换行符
+func lineAt(s string, offset int) string {
+	start := 0
+	for i := 0; i < len(s) && i < offset; {
+		if l := lineBreakLen(s, i); l > 0 {
+			i += l
+			start = i
+			continue
+		}
+		i++
+	}
+	end := start
+	for end < len(s) && lineBreakLen(s, end) == 0 {
+		end++
+	}
+	return s[start:end]
+}
+
 func isWhitespaceNLB(c byte) bool {
 	return c == ' ' || c == '\t'
 }
@@ -41,8 +138,8 @@ func trimStringPart(raw string, endPos int, l int) string {
 	return raw[startPos:endPos]
 }
 
-// findEndOfNumber 用于找到字符串中有效数字的结尾位置
-func findEndOfNumber(s string) int {
+// findEndOfNumber 用于找到字符串中有效数字的结尾位置，allowUnderscore 为 true 时数字中可以包含下划线分隔符
+func findEndOfNumber(s string, allowUnderscore bool) int {
 	sl := len(s)
 
 	if sl == 0 {
@@ -81,6 +178,10 @@ func findEndOfNumber(s string) int {
 
 	// 遍历字符串中的每个字符
 	for i, r := range s {
+		// 下划线分隔符的位置是否合法由 validDigitSeparators 校验
+		if r == '_' && allowUnderscore {
+			continue
+		}
 		switch {
 		// 如果是十六进制
 		case isHex:
@@ -137,3 +238,167 @@ func findEndOfNumber(s string) int {
 	// 遍历完整个字符串，返回字符串的长度
 	return len(s)
 }
+
+// validDigitSeparators 检查数字中的每个下划线是否都位于两个数字之间，
+// 用于拒绝开头、结尾、连续以及紧邻小数点/指数/进制前缀的下划线
+func validDigitSeparators(s string) bool {
+	isHex := len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+	isDigit := func(c byte) bool {
+		return c >= '0' && c <= '9' || isHex && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F')
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteString 将字符串序列化为双引号包裹的JSON字符串，不转义HTML字符
+func quoteString(s string) string {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // 序列化string不会失败
+	return strings.TrimSuffix(buf.String(), lineBreak)
+}
+
+// unquote 去掉字符串字面量首尾的引号（单引号或双引号）并解码其中的转义字符
+func unquote(raw string) (string, error) {
+	if len(raw) < 2 || (raw[0] != '"' && raw[0] != '\'') || raw[len(raw)-1] != raw[0] {
+		return "", fmt.Errorf("invalid string literal: %s", raw)
+	}
+	return decodeEscapes(raw[1 : len(raw)-1])
+}
+
+// decodeEscapes 解码字符串内容中的转义字符，未定义的转义按JSON5规则表示字符本身
+func decodeEscapes(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	buf := &strings.Builder{}
+	buf.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			buf.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", errors.New("invalid escape at end of string")
+		}
+		switch s[i] {
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'v':
+			buf.WriteByte('\v')
+		case 'u':
+			r, size, err := decodeUnicodeEscape(s[i-1:])
+			if err != nil {
+				return "", err
+			}
+			buf.WriteRune(r)
+			i += size - 2
+		case 'x':
+			if i+3 > len(s) {
+				return "", fmt.Errorf("invalid escape: %s", s[i-1:])
+			}
+			b, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape: %s", s[i-1:i+3])
+			}
+			buf.WriteRune(rune(b))
+			i += 2
+		case '0':
+			buf.WriteByte(0)
+		case '\r': // 续行：反斜杠之后的换行不产生字符
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+		default: // \" \' \\ \/ 以及其余字符均表示字符本身
+			if l := lineBreakLen(s, i); l > 0 { // 续行
+				i += l - 1
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(s[i:])
+			buf.WriteRune(r)
+			i += size - 1
+		}
+	}
+	return buf.String(), nil
+}
+
+// decodeUnicodeEscape 解码以 \uXXXX 开头的转义（支持UTF-16代理对），返回字符及消耗的字节数
+func decodeUnicodeEscape(s string) (rune, int, error) {
+	parseHex := func(s string) (rune, bool) {
+		if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(s[2:6], 16, 16)
+		return rune(v), err == nil
+	}
+	r, ok := parseHex(s)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid unicode escape: %s", trimStringPart(s, min(len(s), 6), 6))
+	}
+	if utf16.IsSurrogate(r) {
+		if r2, ok := parseHex(s[6:]); ok {
+			if pair := utf16.DecodeRune(r, r2); pair != utf8.RuneError {
+				return pair, 12, nil
+			}
+		}
+		return utf8.RuneError, 6, nil
+	}
+	return r, 6, nil
+}
+
+// parseNumberLiteral 将JSON5数字字面量转换为float64，支持十六进制、八进制及数字分隔符
+func parseNumberLiteral(s string) (float64, bool) {
+	s = strings.ReplaceAll(s, "_", "")
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	sign := 1.0
+	if len(s) > 1 && (s[0] == '+' || s[0] == '-') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return sign * float64(i), true
+	}
+	return 0, false
+}
+
+// trimLeadingZeros 去掉十进制数字多余的前导0，避免按Go的规则解析为八进制，如 010 转换为 10，00 与 00e1 保留一个0
+func trimLeadingZeros(s string) string {
+	sign, digits := "", s
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		sign, digits = s[:1], s[1:]
+	}
+	if len(digits) < 2 || digits[0] != '0' || digits[1] < '0' || digits[1] > '9' {
+		return s
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" || digits[0] < '0' || digits[0] > '9' {
+		digits = "0" + digits
+	}
+	return sign + digits
+}
+
+// bytesToString 不复制地将data转换为字符串，调用方需保证转换期间data不被修改，且不保留该字符串及其子串
+func bytesToString(data []byte) string {
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
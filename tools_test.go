@@ -0,0 +1,65 @@
+package pjson5
+
+import "testing"
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", raw: `"abc"`, want: "abc"},
+		{name: "single_quote", raw: `'a"b\'c'`, want: `a"b'c`},
+		{name: "backspace", raw: `"a\bb"`, want: "a\bb"},
+		{name: "form_feed", raw: `"rec1\frec2"`, want: "rec1\frec2"},
+		{name: "vertical_tab", raw: `'a\vb'`, want: "a\vb"},
+		{name: "common", raw: `"\n\r\t\\\/"`, want: "\n\r\t\\/"},
+		{name: "unicode", raw: `"\u4e2d\ud83d\ude00"`, want: "中😀"},
+		{name: "identity", raw: `"\a\q"`, want: "aq"},
+		{name: "bad_unicode", raw: `"\u12"`, wantErr: true},
+		{name: "unterminated", raw: `"abc`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unquote(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unquote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("unquote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON5Whitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "nbsp", raw: "{\u00a0\"a\":\u00a01, \"b\": [1, 2] }"},
+		{name: "bom", raw: "\ufeff{\"a\": 1, \"b\": [1, 2]}"},
+		{name: "vt_ff", raw: "{\v\"a\":\f1,\"b\": [1, 2]\v}"},
+		{name: "unicode_spaces", raw: "{\u2003\"a\"\u3000: 1, \"b\": [1,\u202f2] }"},
+		{name: "line_separators", raw: "{\u2028\"a\": 1,\u2029\"b\": [\u2028 1,\u2029 2]\u2028}"},
+		{name: "unquoted_key", raw: "{a\u00a0: 1, b\u2028: [1, 2]}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := New(tt.raw)
+			if v := node.Get("a").Value(); v != "1" {
+				t.Fatalf("expected a=1, got %q (err=%v)", v, node.Error())
+			}
+			if v := node.Get("b.1").Value(); v != "2" {
+				t.Fatalf("expected b.1=2, got %q (err=%v)", v, node.Error())
+			}
+			if reparsed := New(node.Pretty()); reparsed.Get("b.1").Value() != "2" {
+				t.Fatalf("expected output to stay valid, err=%v\n%s", reparsed.Error(), node.Pretty())
+			}
+		})
+	}
+	if New("{\"a\":\u200b1}").Parse().Error() == nil {
+		t.Fatal("expected zero width space to be rejected")
+	}
+}
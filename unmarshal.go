@@ -0,0 +1,177 @@
+package pjson5
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalOptions 控制 UnmarshalWithOptions 的解码行为，零值与 Unmarshal 一致
+type UnmarshalOptions struct {
+	// LenientTypes 按目标字段的类型宽松转换标量：字符串 "123"、"true" 可解码到数字、布尔字段，
+	// 数字与布尔值可解码到字符串字段（使用原文），布尔字段也接受数字（非0为true）。
+	// 无法转换的值保持原样，由 encoding/json 报告类型错误。默认严格匹配类型
+	LenientTypes bool
+}
+
+// Unmarshal 将节点的值解码到v中，注释与尾随逗号会被忽略，单引号字符串、无引号的key、十六进制数字等
+// JSON5写法会先转换为标准JSON再交给 encoding/json 解码，v的要求与 json.Unmarshal 相同
+func (n *Node) Unmarshal(v any) error {
+	return n.UnmarshalWithOptions(v, UnmarshalOptions{})
+}
+
+// UnmarshalWithOptions 使用指定的选项将节点的值解码到v中
+func (n *Node) UnmarshalWithOptions(v any, opts UnmarshalOptions) error {
+	buf := &strings.Builder{}
+	var err error
+	if t := reflect.TypeOf(v); opts.LenientTypes && t != nil && t.Kind() == reflect.Pointer {
+		err = writeLenientJSON(buf, n, t.Elem(), nil)
+	} else {
+		err = writeStrictJSON(buf, n, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(buf.String()), v)
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// writeLenientJSON 与 writeStrictJSON 相同，但会按目标类型t转换标量的类型，t为nil时不做转换
+func writeLenientJSON(buf *strings.Builder, node *Node, t reflect.Type, path []string) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// 自定义解码的类型按原值交给其自身处理
+	if t == nil || reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return writeStrictJSON(buf, node, path)
+	}
+	if node.parse().Error() != nil {
+		return node.err
+	}
+	switch node.typ {
+	case Object, Array:
+		pair := objectPair
+		if node.typ == Array {
+			pair = arrayPair
+		}
+		buf.WriteByte(pair[0])
+		for i, key := range node.orderedKeys() {
+			if i > 0 {
+				buf.WriteByte(comma)
+			}
+			if node.typ == Object {
+				buf.WriteString(quoteString(key))
+				buf.WriteByte(colon)
+			}
+			if err := writeLenientJSON(buf, node.children[key], elemType(t, node.typ, key), append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(pair[1])
+		return nil
+	}
+	if s, ok := coerceScalar(node, t.Kind()); ok {
+		buf.WriteString(s)
+		return nil
+	}
+	return writeStrictJSON(buf, node, path)
+}
+
+// elemType 返回容器类型t中key对应的成员类型，无法确定时返回nil
+func elemType(t reflect.Type, typ Type, key string) reflect.Type {
+	switch t.Kind() {
+	case reflect.Map:
+		if typ == Object {
+			return t.Elem()
+		}
+	case reflect.Slice, reflect.Array:
+		if typ == Array {
+			return t.Elem()
+		}
+	case reflect.Struct:
+		if typ == Object {
+			return fieldType(t, key)
+		}
+	}
+	return nil
+}
+
+// fieldType 按 encoding/json 的规则（json tag优先，名称不区分大小写）查找key对应的字段类型，
+// 包括匿名嵌入结构体中的字段
+func fieldType(t reflect.Type, key string) reflect.Type {
+	var fold reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if embedded := fieldType(ft, key); embedded != nil && fold == nil {
+				fold = embedded
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f.Type
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = f.Type
+		}
+	}
+	return fold
+}
+
+// coerceScalar 将标量节点转换为kind类型对应的JSON值，无需或无法转换时返回false
+func coerceScalar(node *Node, kind reflect.Kind) (string, bool) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		switch node.typ {
+		case String:
+			s, err := unquote(node.val)
+			if err != nil {
+				return "", false
+			}
+			num, err := strictNumber(strings.TrimSpace(s))
+			return num, err == nil
+		case Boolean:
+			if node.val == "true" {
+				return "1", true
+			}
+			return "0", true
+		}
+	case reflect.Bool:
+		switch node.typ {
+		case String:
+			s, err := unquote(node.val)
+			if err != nil {
+				return "", false
+			}
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			return strconv.FormatBool(b), err == nil
+		case Number:
+			f, ok := parseNumberLiteral(strings.ReplaceAll(node.val, "_", ""))
+			return strconv.FormatBool(f != 0), ok
+		}
+	case reflect.String:
+		if node.typ == Number || node.typ == Boolean {
+			return quoteString(node.val), true
+		}
+	}
+	return "", false
+}
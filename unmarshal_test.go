@@ -0,0 +1,86 @@
+package pjson5
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNode_Unmarshal(t *testing.T) {
+	type mapKey struct {
+		Name     string `json:"name"`
+		Val      int    `json:"val"`
+		DataList []int  `json:"data_list"`
+	}
+	var got struct {
+		NumberKey int    `json:"number_key"`
+		StringKey string `json:"string_key"`
+		MapKey    mapKey `json:"map_key"`
+	}
+	if err := New(rawJson).Unmarshal(&got); err != nil {
+		t.Fatal("Unmarshal() error:", err)
+	}
+	if got.NumberKey != 2 || got.StringKey != "www.com" || !reflect.DeepEqual(got.MapKey, mapKey{"This is name", 60000, []int{5000}}) {
+		t.Fatalf("Unmarshal() = %+v", got)
+	}
+	var sub mapKey
+	if err := New(rawJson).Get("map_key").Unmarshal(&sub); err != nil || sub.Name != "This is name" {
+		t.Fatalf("Get().Unmarshal() = %+v, %v", sub, err)
+	}
+	// JSON5写法：无引号的key、单引号字符串、十六进制数字、+号、尾随逗号与注释
+	var extras map[string]any
+	data := "{\n  name: 'it\\'s', // 名称\n  mask: 0xFF,\n  scale: +.5,\n  tags: ['a', \"b\",],\n}"
+	if err := New(data).Unmarshal(&extras); err != nil {
+		t.Fatal("Unmarshal() error:", err)
+	}
+	want := map[string]any{"name": "it's", "mask": 255.0, "scale": 0.5, "tags": []any{"a", "b"}}
+	if !reflect.DeepEqual(extras, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", extras, want)
+	}
+}
+
+func TestNode_UnmarshalWithOptions(t *testing.T) {
+	type Base struct {
+		Debug bool
+	}
+	type config struct {
+		Base
+		Port    int               `json:"port"`
+		Ratio   *float64          `json:"ratio"`
+		Version string            `json:"version"`
+		Retries []uint            `json:"retries"`
+		Labels  map[string]string `json:"labels"`
+		Extra   any               `json:"extra"`
+	}
+	raw := `{
+  debug: 'true',
+  port: " 8080 ",
+  ratio: '0.5',
+  version: 2, // 写成了数字
+  retries: ['1', 0x2, true],
+  labels: {zone: 1, on: false},
+  extra: '42',
+}`
+	if err := New(raw).Unmarshal(&config{}); err == nil {
+		t.Fatal("expected type error without LenientTypes")
+	}
+	var got config
+	if err := New(raw).UnmarshalWithOptions(&got, UnmarshalOptions{LenientTypes: true}); err != nil {
+		t.Fatal("UnmarshalWithOptions() error:", err)
+	}
+	ratio := 0.5
+	want := config{
+		Base:    Base{Debug: true},
+		Port:    8080,
+		Ratio:   &ratio,
+		Version: "2",
+		Retries: []uint{1, 2, 1},
+		Labels:  map[string]string{"zone": "1", "on": "false"},
+		Extra:   "42",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnmarshalWithOptions() = %+v, want %+v", got, want)
+	}
+	if err := New(`{port: 'abc'}`).UnmarshalWithOptions(&got, UnmarshalOptions{LenientTypes: true}); err == nil {
+		t.Fatal("expected type error for non-numeric string")
+	}
+}
@@ -0,0 +1,51 @@
+package pjson5
+
+import "strings"
+
+// orderedKeys 按文档顺序返回Object的key或Array的下标，重复的key只返回一次
+func (n *Node) orderedKeys() []string {
+	keys := make([]string, 0, len(n.children))
+	seen := make(map[string]bool, len(n.children))
+	for _, block := range n.block {
+		var key string
+		switch {
+		case n.typ == Object && block.Typ == dataTypeKey:
+			key = block.KeyUnQuot()
+		case n.typ == Array && block.Typ == dataTypeVal:
+			key = block.Val
+		default:
+			continue
+		}
+		if _, ok := n.children[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// orderedChildren 按文档顺序返回Object/Array的子节点
+func (n *Node) orderedChildren() []*Node {
+	keys := n.orderedKeys()
+	children := make([]*Node, 0, len(keys))
+	for _, key := range keys {
+		children = append(children, n.children[key])
+	}
+	return children
+}
+
+// walk 按文档顺序深度优先遍历节点树，path为节点相对于遍历起点的路径，len(path)即节点深度。
+// fn返回false时不再遍历该节点的子节点；解析失败的节点不会再向下遍历
+func (n *Node) walk(path []string, fn func(path []string, node *Node) bool) {
+	if !fn(path, n) || n.parse().Error() != nil {
+		return
+	}
+	for _, key := range n.orderedKeys() {
+		n.children[key].walk(append(path[:len(path):len(path)], key), fn)
+	}
+}
+
+// joinPath 将路径片段拼接为点分路径
+func joinPath(path []string) string {
+	return strings.Join(path, ".")
+}